@@ -0,0 +1,55 @@
+// minttoken AUTH_MODE=hs256 で起動したサーバー向けに、ローカル開発用のアクセストークンを発行します
+//
+//	go run ./cmd/minttoken -sub <user-id> [-email a@example.com] [-ttl 1h] [-roles admin]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	userMiddleware "github.com/RiTa-23/TRI-Survivor/backend/internal/middleware"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	sub := flag.String("sub", "", "user id (sub claim)")
+	email := flag.String("email", "", "email claim")
+	ttl := flag.Duration("ttl", time.Hour, "token lifetime")
+	roles := flag.String("roles", "", "comma-separated app_metadata.roles")
+	flag.Parse()
+
+	_ = godotenv.Load()
+	secret := os.Getenv("AUTH_JWT_SECRET")
+	if secret == "" {
+		log.Fatal("AUTH_JWT_SECRET is not set")
+	}
+	if *sub == "" {
+		log.Fatal("-sub is required")
+	}
+
+	// サーバーと同じ検証条件を満たすように発行元と対象を合わせる
+	extra := jwt.MapClaims{}
+	if iss := os.Getenv("AUTH_ISSUER"); iss != "" {
+		extra["iss"] = iss
+	}
+	if aud := os.Getenv("AUTH_AUDIENCE"); aud != "" {
+		extra["aud"] = aud
+	}
+	if *email != "" {
+		extra["email"] = *email
+	}
+	if *roles != "" {
+		extra["app_metadata"] = map[string]any{"roles": strings.Split(*roles, ",")}
+	}
+
+	token, err := userMiddleware.MintHS256Token([]byte(secret), *sub, *ttl, extra)
+	if err != nil {
+		log.Fatalf("failed to mint token: %v", err)
+	}
+	fmt.Println(token)
+}
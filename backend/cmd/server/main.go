@@ -1,13 +1,16 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
 	"strings"
+	_ "time/tzdata" // GAME_TIMEZONE を tzdata の無いコンテナでも解決できるようにする
 
 	"github.com/RiTa-23/TRI-Survivor/backend/internal/handler"
 	"github.com/RiTa-23/TRI-Survivor/backend/internal/infrastructure"
+	userMiddleware "github.com/RiTa-23/TRI-Survivor/backend/internal/middleware"
 	"github.com/RiTa-23/TRI-Survivor/backend/internal/repository"
 	"github.com/RiTa-23/TRI-Survivor/backend/internal/router"
 	"github.com/RiTa-23/TRI-Survivor/backend/internal/service"
@@ -31,22 +34,96 @@ func main() {
 	log.Println("Connected to Database")
 
 	// Initialize Dependencies
+	txManager := repository.NewTxManager(db)
+
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
+	coinTxRepo := repository.NewCoinTransactionRepository(db)
+	userService := service.NewUserService(userRepo, coinTxRepo, txManager)
 	userHandler := handler.NewUserHandler(userService)
 
 	settingsRepo := repository.NewSettingsRepository(db)
-	settingsService := service.NewSettingsService(settingsRepo)
+	settingsProfileRepo := repository.NewSettingsProfileRepository(db)
+	settingsService := service.NewSettingsService(settingsRepo, settingsProfileRepo, userRepo, txManager)
 	settingsHandler := handler.NewSettingsHandler(settingsService)
 
+	itemRepo := repository.NewItemRepository(db)
+
 	shopRepo := repository.NewShopRepository(db)
-	shopService := service.NewShopService(shopRepo)
+	shopService := service.NewShopService(shopRepo, itemRepo, userService, txManager)
 	shopHandler := handler.NewShopHandler(shopService)
 
-	itemRepo := repository.NewItemRepository(db)
+	shopAuditLogRepo := repository.NewShopAuditLogRepository(db)
+	shopAdminService := service.NewShopAdminService(shopRepo, shopAuditLogRepo, txManager)
+	adminShopHandler := handler.NewAdminShopHandler(shopAdminService)
+
 	itemService := service.NewItemService(itemRepo)
 	itemHandler := handler.NewItemHandler(itemService)
 
+	gameCalendar := service.LoadGameCalendar()
+
+	leaderboardRepo := repository.NewLeaderboardRepository(db)
+	leaderboardService := service.NewLeaderboardService(leaderboardRepo, gameCalendar, txManager)
+	// 日別・週別ランキングの期間をGAME_TIMEZONEに合わせる（変更された場合のみ集計し直す）
+	if rebuilt, err := leaderboardService.SyncTimezone(context.Background()); err != nil {
+		log.Fatalf("failed to sync leaderboard timezone: %v", err)
+	} else if rebuilt {
+		log.Printf("Rebuilt daily/weekly leaderboards for GAME_TIMEZONE %s", gameCalendar.Location())
+	}
+	leaderboardHandler := handler.NewLeaderboardHandler(leaderboardService)
+
+	runRepo := repository.NewRunRepository(db)
+
+	achievementRepo := repository.NewAchievementRepository(db)
+	achievementService := service.NewAchievementService(achievementRepo, runRepo, userService)
+	achievementHandler := handler.NewAchievementHandler(achievementService)
+
+	missionRepo := repository.NewMissionRepository(db)
+	missionService := service.NewMissionService(missionRepo, userService, gameCalendar, service.LoadMissionConfig(), txManager)
+	missionHandler := handler.NewMissionHandler(missionService)
+
+	unlockableRepo := repository.NewUnlockableRepository(db)
+	unlockService := service.NewUnlockService(unlockableRepo, achievementRepo, runRepo, userService, txManager)
+	unlockHandler := handler.NewUnlockHandler(unlockService)
+
+	loadoutRepo := repository.NewLoadoutRepository(db)
+	loadoutService := service.NewLoadoutService(loadoutRepo, itemRepo, unlockService)
+	loadoutHandler := handler.NewLoadoutHandler(loadoutService)
+
+	runReviewRepo := repository.NewRunReviewRepository(db)
+	runConsumableRepo := repository.NewRunConsumableRepository(db)
+	consumableService := service.NewConsumableService(runConsumableRepo, itemRepo, shopRepo)
+	runValidator := service.NewRunValidator(service.LoadRunValidationConfig())
+	runService := service.NewRunService(runRepo, runReviewRepo, runValidator, userService, leaderboardService, achievementService, unlockService, missionService, loadoutService, consumableService, service.LoadRunConfig(), txManager)
+	runHandler := handler.NewRunHandler(runService, gameCalendar)
+
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
+	userRoleRepo := repository.NewUserRoleRepository(db)
+
+	// AUTH_MODEに応じてアクセストークンの検証方法を選択する
+	// リモートのJWKSは取得できるまでバックグラウンドで再試行する（/api/readyで状況を確認できる）
+	verifier, err := userMiddleware.NewTokenVerifierFromEnv(context.Background())
+	if err != nil {
+		log.Fatalf("failed to configure token verifier: %v", err)
+	}
+	healthHandler := handler.NewHealthHandler(db, verifier)
+
+	// GUEST_TOKEN_SECRETが設定されている場合はゲストアカウントを有効にする
+	guestTokens, err := userMiddleware.NewGuestTokensFromEnv()
+	if err != nil {
+		log.Fatalf("failed to configure guest tokens: %v", err)
+	}
+	verifier = userMiddleware.WithGuestTokens(verifier, guestTokens)
+	guestService := service.NewGuestService(userRepo, settingsRepo, settingsProfileRepo, itemRepo, runRepo, runReviewRepo, leaderboardRepo, achievementRepo, unlockableRepo, coinTxRepo, userService, txManager)
+	guestHandler := handler.NewGuestHandler(guestService, guestTokens)
+
+	// 削除申請から猶予期間が過ぎたアカウントは定期的に完全に削除する
+	accountService := service.NewAccountService(userRepo, settingsRepo, settingsProfileRepo, itemRepo, coinTxRepo, runRepo, achievementRepo, unlockableRepo, loadoutRepo, service.LoadAccountConfig())
+	accountService.StartPurger(context.Background())
+	accountHandler := handler.NewAccountHandler(accountService)
+
+	profileService := service.NewProfileService(userRepo, settingsRepo, runRepo, achievementRepo, txManager, service.LoadProfileConfig())
+	profileHandler := handler.NewProfileHandler(profileService)
+
 	// Initialize Echo
 	e := echo.New()
 
@@ -63,13 +140,14 @@ func main() {
 
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins:     allowOrigins,
-		AllowMethods:     []string{http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete, http.MethodOptions},
-		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
+		AllowMethods:     []string{http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodPost, http.MethodDelete, http.MethodOptions},
+		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, userMiddleware.HeaderIdempotencyKey, handler.HeaderIfMatch},
+		ExposeHeaders:    []string{userMiddleware.HeaderIdempotentReplayed, echo.HeaderContentDisposition, handler.HeaderETag},
 		AllowCredentials: true,
 	}))
 
 	// Setup Router
-	router.SetupRouter(e, userHandler, settingsHandler, shopHandler, itemHandler)
+	router.SetupRouter(e, userHandler, settingsHandler, shopHandler, itemHandler, runHandler, leaderboardHandler, achievementHandler, missionHandler, loadoutHandler, unlockHandler, adminShopHandler, idempotencyRepo, userRepo, userRoleRepo, verifier, healthHandler, guestHandler, accountHandler, profileHandler)
 
 	// Start Server
 	e.Logger.Fatal(e.Start(":8080"))
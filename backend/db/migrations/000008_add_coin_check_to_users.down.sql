@@ -0,0 +1 @@
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_coin_non_negative;
@@ -0,0 +1 @@
+ALTER TABLE users ADD CONSTRAINT users_coin_non_negative CHECK (coin >= 0);
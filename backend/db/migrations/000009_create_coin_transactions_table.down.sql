@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS prevent_coin_transactions_update ON coin_transactions;
+DROP FUNCTION IF EXISTS prevent_coin_transactions_update();
+DROP TABLE IF EXISTS coin_transactions;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS coin_transactions (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  user_id UUID NOT NULL,
+  delta INTEGER NOT NULL CHECK (delta <> 0),
+  reason TEXT NOT NULL,
+  reference_id TEXT,
+  balance_after INTEGER NOT NULL CHECK (balance_after >= 0),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT coin_transactions_user_fk FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS coin_transactions_user_id_idx ON coin_transactions (user_id, id DESC);
+
+-- 台帳は追記専用とし、既存レコードの書き換えを禁止する
+CREATE OR REPLACE FUNCTION prevent_coin_transactions_update()
+RETURNS TRIGGER AS $$
+BEGIN
+  RAISE EXCEPTION 'coin_transactions is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER prevent_coin_transactions_update
+BEFORE UPDATE ON coin_transactions
+FOR EACH ROW
+EXECUTE FUNCTION prevent_coin_transactions_update();
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS set_runs_updated_at ON runs;
+DROP TABLE IF EXISTS runs;
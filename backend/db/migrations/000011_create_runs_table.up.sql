@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS runs (
+  id UUID NOT NULL DEFAULT gen_random_uuid() PRIMARY KEY,
+  user_id UUID NOT NULL,
+  nonce TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'in_progress' CHECK (status IN ('in_progress', 'finished')),
+  started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  finished_at TIMESTAMPTZ,
+  survival_time DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (survival_time >= 0),
+  kill_count INTEGER NOT NULL DEFAULT 0 CHECK (kill_count >= 0),
+  level INTEGER NOT NULL DEFAULT 1 CHECK (level >= 1),
+  coins INTEGER NOT NULL DEFAULT 0 CHECK (coins >= 0),
+  weapons JSONB NOT NULL DEFAULT '[]'::jsonb,
+  passives JSONB NOT NULL DEFAULT '[]'::jsonb,
+  special_type TEXT,
+  cleared BOOLEAN NOT NULL DEFAULT false,
+  coin_reward INTEGER NOT NULL DEFAULT 0 CHECK (coin_reward >= 0),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT runs_user_fk FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS runs_user_id_started_at_idx ON runs (user_id, started_at DESC);
+
+CREATE TRIGGER set_runs_updated_at
+BEFORE UPDATE ON runs
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS set_run_reviews_updated_at ON run_reviews;
+DROP TABLE IF EXISTS run_reviews;
+
+UPDATE runs SET status = 'finished' WHERE status = 'flagged';
+ALTER TABLE runs DROP CONSTRAINT IF EXISTS runs_status_check;
+ALTER TABLE runs ADD CONSTRAINT runs_status_check CHECK (status IN ('in_progress', 'finished'));
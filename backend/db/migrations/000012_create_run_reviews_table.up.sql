@@ -0,0 +1,20 @@
+ALTER TABLE runs DROP CONSTRAINT IF EXISTS runs_status_check;
+ALTER TABLE runs ADD CONSTRAINT runs_status_check CHECK (status IN ('in_progress', 'finished', 'flagged'));
+
+CREATE TABLE IF NOT EXISTS run_reviews (
+  run_id UUID NOT NULL PRIMARY KEY,
+  user_id UUID NOT NULL,
+  reasons JSONB NOT NULL DEFAULT '[]'::jsonb,
+  status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT run_reviews_run_fk FOREIGN KEY (run_id) REFERENCES runs (id) ON DELETE CASCADE,
+  CONSTRAINT run_reviews_user_fk FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS run_reviews_status_idx ON run_reviews (status, created_at);
+
+CREATE TRIGGER set_run_reviews_updated_at
+BEFORE UPDATE ON run_reviews
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
@@ -0,0 +1,42 @@
+-- ランキング用の集計テーブル
+-- ボード・期間ごとにユーザーの自己ベストのみを保持する
+-- rank_value は大きいほど上位になるよう正規化した値（最速クリアのように小さいほど良いボードは符号を反転する）
+CREATE TABLE IF NOT EXISTS leaderboard_entries (
+  board TEXT NOT NULL,
+  period TEXT NOT NULL CHECK (period IN ('all', 'daily', 'weekly')),
+  period_start DATE NOT NULL,
+  user_id UUID NOT NULL,
+  run_id UUID NOT NULL,
+  score DOUBLE PRECISION NOT NULL,
+  rank_value DOUBLE PRECISION NOT NULL,
+  achieved_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (board, period, period_start, user_id),
+  CONSTRAINT leaderboard_entries_user_fk FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+  CONSTRAINT leaderboard_entries_run_fk FOREIGN KEY (run_id) REFERENCES runs (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS leaderboard_entries_rank_idx
+  ON leaderboard_entries (board, period, period_start, rank_value DESC, achieved_at ASC);
+
+-- 既存のランから集計を作成する（期間の区切りはデフォルトの GAME_TIMEZONE である Asia/Tokyo 基準）
+INSERT INTO leaderboard_entries (board, period, period_start, user_id, run_id, score, rank_value, achieved_at)
+SELECT DISTINCT ON (board, period, period_start, user_id)
+  board, period, period_start, user_id, run_id, score, rank_value, achieved_at
+FROM (
+  SELECT r.id AS run_id, r.user_id, r.finished_at AS achieved_at, b.board, b.score, b.rank_value, p.period, p.period_start
+  FROM runs r
+  CROSS JOIN LATERAL (VALUES
+    ('survival', r.survival_time, r.survival_time),
+    ('kills', r.kill_count::double precision, r.kill_count::double precision),
+    ('fastest_clear',
+      CASE WHEN r.cleared THEN EXTRACT(EPOCH FROM r.finished_at - r.started_at)::double precision END,
+      CASE WHEN r.cleared THEN -EXTRACT(EPOCH FROM r.finished_at - r.started_at)::double precision END)
+  ) AS b (board, score, rank_value)
+  CROSS JOIN LATERAL (VALUES
+    ('all', DATE '1970-01-01'),
+    ('daily', (r.finished_at AT TIME ZONE 'Asia/Tokyo')::date),
+    ('weekly', date_trunc('week', r.finished_at AT TIME ZONE 'Asia/Tokyo')::date)
+  ) AS p (period, period_start)
+  WHERE r.status = 'finished' AND r.finished_at IS NOT NULL AND b.score IS NOT NULL
+) AS candidates
+ORDER BY board, period, period_start, user_id, rank_value DESC, achieved_at ASC;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS user_achievements;
+DROP TRIGGER IF EXISTS set_achievements_updated_at ON achievements;
+DROP TABLE IF EXISTS achievements;
@@ -0,0 +1,41 @@
+CREATE TABLE IF NOT EXISTS achievements (
+  id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  code TEXT NOT NULL UNIQUE,
+  name TEXT NOT NULL,
+  description TEXT NOT NULL,
+  rule_type TEXT NOT NULL,
+  target INTEGER NOT NULL CHECK (target > 0),
+  params JSONB NOT NULL DEFAULT '{}'::jsonb,
+  reward_coin INTEGER NOT NULL DEFAULT 0 CHECK (reward_coin >= 0),
+  sort_order INTEGER NOT NULL DEFAULT 0,
+  is_active BOOLEAN NOT NULL DEFAULT true,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TRIGGER set_achievements_updated_at
+BEFORE UPDATE ON achievements
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS user_achievements (
+  user_id UUID NOT NULL,
+  achievement_id INTEGER NOT NULL,
+  run_id UUID,
+  unlocked_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (user_id, achievement_id),
+  CONSTRAINT user_achievements_user_fk FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+  CONSTRAINT user_achievements_achievement_fk FOREIGN KEY (achievement_id) REFERENCES achievements (id) ON DELETE CASCADE,
+  CONSTRAINT user_achievements_run_fk FOREIGN KEY (run_id) REFERENCES runs (id) ON DELETE SET NULL
+);
+
+INSERT INTO achievements (code, name, description, rule_type, target, params, reward_coin, sort_order) VALUES
+  ('first_clear', '初クリア', 'ゲームを1回クリアする', 'total_clears', 1, '{}', 100, 10),
+  ('clear_10', '熟練サバイバー', 'ゲームを10回クリアする', 'total_clears', 10, '{}', 500, 20),
+  ('kill_1000', '千体斬り', '通算で敵を1000体倒す', 'total_kills', 1000, '{}', 200, 30),
+  ('kill_10000', '万夫不当', '通算で敵を10000体倒す', 'total_kills', 10000, '{}', 1000, 40),
+  ('kills_in_run_500', '殲滅者', '1回のプレイで敵を500体倒す', 'kills_in_run', 500, '{}', 200, 50),
+  ('reach_level_20', '成長の証', '1回のプレイでレベル20に到達する', 'reach_level', 20, '{}', 200, 60),
+  ('survive_180', '3分間の死闘', '1回のプレイで3分間生き残る', 'survival_time', 180, '{}', 100, 70),
+  ('clear_sword_only', '剣一筋', '武器をカタナのみでクリアする', 'clear_with_only_weapon', 1, '{"weapon": "SWORD"}', 300, 80),
+  ('clear_gun_only', '早撃ち名人', '武器をピストルのみでクリアする', 'clear_with_only_weapon', 1, '{"weapon": "GUN"}', 300, 90);
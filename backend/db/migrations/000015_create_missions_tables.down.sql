@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS set_user_missions_updated_at ON user_missions;
+DROP TABLE IF EXISTS user_missions;
+DROP TRIGGER IF EXISTS set_mission_templates_updated_at ON mission_templates;
+DROP TABLE IF EXISTS mission_templates;
@@ -0,0 +1,57 @@
+CREATE TABLE IF NOT EXISTS mission_templates (
+  id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  code TEXT NOT NULL UNIQUE,
+  period TEXT NOT NULL CHECK (period IN ('daily', 'weekly')),
+  title TEXT NOT NULL,
+  description TEXT NOT NULL,
+  objective TEXT NOT NULL,
+  target INTEGER NOT NULL CHECK (target > 0),
+  params JSONB NOT NULL DEFAULT '{}'::jsonb,
+  reward_coin INTEGER NOT NULL DEFAULT 0 CHECK (reward_coin >= 0),
+  weight INTEGER NOT NULL DEFAULT 1 CHECK (weight > 0),
+  is_active BOOLEAN NOT NULL DEFAULT true,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TRIGGER set_mission_templates_updated_at
+BEFORE UPDATE ON mission_templates
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS user_missions (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  user_id UUID NOT NULL,
+  template_id INTEGER NOT NULL,
+  period TEXT NOT NULL CHECK (period IN ('daily', 'weekly')),
+  period_start DATE NOT NULL,
+  expires_at TIMESTAMPTZ NOT NULL,
+  progress INTEGER NOT NULL DEFAULT 0 CHECK (progress >= 0),
+  target INTEGER NOT NULL CHECK (target > 0),
+  reward_coin INTEGER NOT NULL DEFAULT 0 CHECK (reward_coin >= 0),
+  completed_at TIMESTAMPTZ,
+  claimed_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT user_missions_user_fk FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+  CONSTRAINT user_missions_template_fk FOREIGN KEY (template_id) REFERENCES mission_templates (id) ON DELETE CASCADE,
+  CONSTRAINT user_missions_unique UNIQUE (user_id, template_id, period_start)
+);
+
+CREATE INDEX IF NOT EXISTS user_missions_user_period_idx ON user_missions (user_id, period, period_start);
+
+CREATE TRIGGER set_user_missions_updated_at
+BEFORE UPDATE ON user_missions
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+INSERT INTO mission_templates (code, period, title, description, objective, target, params, reward_coin, weight) VALUES
+  ('daily_kill_300', 'daily', '300体討伐', '今日中に敵を合計300体倒す', 'kill_count', 300, '{}', 50, 3),
+  ('daily_kill_800', 'daily', '800体討伐', '今日中に敵を合計800体倒す', 'kill_count', 800, '{}', 100, 1),
+  ('daily_play_3', 'daily', '3回出撃', '今日中に3回プレイする', 'runs', 3, '{}', 30, 2),
+  ('daily_survive_180_kon', 'daily', '狐と共に', '必殺技「狐」を装備して3分間生き残る', 'survival_time', 180, '{"specialType": "KON"}', 80, 1),
+  ('daily_survive_180_muryo', 'daily', '無量の守り', '必殺技「無量空処」を装備して3分間生き残る', 'survival_time', 180, '{"specialType": "MURYO_KUSHO"}', 80, 1),
+  ('daily_survive_120_sword', 'daily', '剣の修行', 'カタナを所持して2分間生き残る', 'survival_time', 120, '{"weapon": "SWORD"}', 60, 1),
+  ('weekly_clear_3', 'weekly', '週間クリア', '今週中に3回クリアする', 'clears', 3, '{}', 300, 2),
+  ('weekly_kill_5000', 'weekly', '5000体討伐', '今週中に敵を合計5000体倒す', 'kill_count', 5000, '{}', 300, 2),
+  ('weekly_play_15', 'weekly', '15回出撃', '今週中に15回プレイする', 'runs', 15, '{}', 200, 1);
@@ -0,0 +1,7 @@
+DELETE FROM shop WHERE skill_type IS NOT NULL;
+DROP INDEX IF EXISTS shop_skill_type_unique;
+ALTER TABLE shop DROP CONSTRAINT IF EXISTS shop_upgrade_columns;
+ALTER TABLE shop
+  DROP COLUMN IF EXISTS level_prices,
+  DROP COLUMN IF EXISTS max_level,
+  DROP COLUMN IF EXISTS skill_type;
@@ -0,0 +1,24 @@
+-- 恒久強化（レベル制のステータスアップ）。items.quantityを現在のレベルとして扱う
+ALTER TABLE shop
+  ADD COLUMN skill_type TEXT,
+  ADD COLUMN max_level INTEGER,
+  ADD COLUMN level_prices JSONB;
+
+ALTER TABLE shop ADD CONSTRAINT shop_upgrade_columns CHECK (
+  (skill_type IS NULL AND max_level IS NULL AND level_prices IS NULL)
+  OR (
+    skill_type IS NOT NULL
+    AND max_level > 0
+    AND jsonb_typeof(level_prices) = 'array'
+    AND jsonb_array_length(level_prices) = max_level
+  )
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS shop_skill_type_unique ON shop (skill_type) WHERE skill_type IS NOT NULL;
+
+INSERT INTO shop (item_name, description, price, item_type, icon_url, skill_type, max_level, level_prices) VALUES
+  ('攻撃力アップ', '開始時の攻撃力が 2 上昇する', 180, 'status', '/assets/images/skills/atk.png', 'ATTACK_UP', 3, '[180, 360, 720]'),
+  ('防御力アップ', '開始時から受けるダメージを 10% 軽減する', 150, 'status', '/assets/images/skills/def.png', 'DEFENSE_UP', 3, '[150, 300, 600]'),
+  ('スピードアップ', '開始時の移動速度が 10% 上昇する', 120, 'status', '/assets/images/skills/spd.png', 'SPEED_UP', 3, '[120, 240, 480]'),
+  ('マグネット範囲', '開始時のアイテム回収範囲が 25% 広がる', 100, 'status', '/assets/images/skills/pck.png', 'MAGNET_UP', 3, '[100, 200, 400]'),
+  ('成長促進', '開始時から経験値獲得量が 10% 増加する', 150, 'status', '/assets/images/skills/exp.png', 'EXP_UP', 3, '[150, 300, 600]');
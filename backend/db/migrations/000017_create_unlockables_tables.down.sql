@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS user_unlocks;
+DROP TRIGGER IF EXISTS set_unlockables_updated_at ON unlockables;
+DROP TABLE IF EXISTS unlockables;
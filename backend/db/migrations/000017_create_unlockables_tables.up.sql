@@ -0,0 +1,38 @@
+-- 武器・必殺技の解放。code はクライアントの SkillType / SpecialSkillType と一致させる
+CREATE TABLE IF NOT EXISTS unlockables (
+  id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  code TEXT NOT NULL UNIQUE,
+  kind TEXT NOT NULL CHECK (kind IN ('weapon', 'special')),
+  name TEXT NOT NULL,
+  description TEXT NOT NULL,
+  condition_type TEXT NOT NULL CHECK (condition_type IN ('default', 'coin', 'achievement', 'level')),
+  price INTEGER NOT NULL DEFAULT 0 CHECK (price >= 0),
+  params JSONB NOT NULL DEFAULT '{}'::jsonb,
+  sort_order INTEGER NOT NULL DEFAULT 0,
+  is_active BOOLEAN NOT NULL DEFAULT true,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT unlockables_coin_price CHECK (condition_type <> 'coin' OR price > 0)
+);
+
+CREATE TRIGGER set_unlockables_updated_at
+BEFORE UPDATE ON unlockables
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS user_unlocks (
+  user_id UUID NOT NULL,
+  unlockable_id INTEGER NOT NULL,
+  source TEXT NOT NULL CHECK (source IN ('coin', 'achievement', 'level', 'grant')),
+  unlocked_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (user_id, unlockable_id),
+  CONSTRAINT user_unlocks_user_fk FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+  CONSTRAINT user_unlocks_unlockable_fk FOREIGN KEY (unlockable_id) REFERENCES unlockables (id) ON DELETE CASCADE
+);
+
+-- 現在の武器・必殺技は全員が使えるため、初期解放として登録する
+INSERT INTO unlockables (code, kind, name, description, condition_type, sort_order) VALUES
+  ('GUN', 'weapon', 'ピストル', '近くの敵を自動で攻撃する', 'default', 10),
+  ('SWORD', 'weapon', 'カタナ', '前方の敵を斬りつける', 'default', 20),
+  ('MURYO_KUSHO', 'special', '無量空処', '領域を展開して敵を攻撃する', 'default', 10),
+  ('KON', 'special', '狐', '狐の式神で敵を攻撃する', 'default', 20);
@@ -0,0 +1,3 @@
+ALTER TABLE runs DROP COLUMN IF EXISTS loadout;
+DROP TRIGGER IF EXISTS set_loadouts_updated_at ON loadouts;
+DROP TABLE IF EXISTS loadouts;
@@ -0,0 +1,21 @@
+-- ラン開始前に選ぶ装備のプリセット（ユーザーごとに名前で管理する）
+CREATE TABLE IF NOT EXISTS loadouts (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  user_id UUID NOT NULL,
+  name TEXT NOT NULL CHECK (char_length(name) BETWEEN 1 AND 32),
+  weapon TEXT NOT NULL,
+  special_type TEXT NOT NULL,
+  consumables JSONB NOT NULL DEFAULT '[]'::jsonb,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT loadouts_user_fk FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+  CONSTRAINT loadouts_user_name_unique UNIQUE (user_id, name)
+);
+
+CREATE TRIGGER set_loadouts_updated_at
+BEFORE UPDATE ON loadouts
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+-- ラン開始時点の装備のスナップショット（プリセットを後から変更しても記録は変わらない）
+ALTER TABLE runs ADD COLUMN loadout JSONB;
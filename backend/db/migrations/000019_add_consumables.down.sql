@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS run_consumables;
+
+DELETE FROM runs WHERE status = 'aborted';
+ALTER TABLE runs DROP CONSTRAINT IF EXISTS runs_status_check;
+ALTER TABLE runs ADD CONSTRAINT runs_status_check CHECK (status IN ('in_progress', 'finished', 'flagged'));
+
+DELETE FROM shop WHERE effect IS NOT NULL;
+ALTER TABLE shop DROP CONSTRAINT IF EXISTS shop_effect_not_upgrade;
+ALTER TABLE shop DROP COLUMN IF EXISTS effect;
@@ -0,0 +1,24 @@
+-- 消費アイテム。ラン開始時に持ち込んだ分だけ items.quantity から減らす
+ALTER TABLE shop ADD COLUMN effect TEXT CHECK (effect IN ('revive', 'exp_boost', 'coin_doubler'));
+ALTER TABLE shop ADD CONSTRAINT shop_effect_not_upgrade CHECK (effect IS NULL OR skill_type IS NULL);
+
+INSERT INTO shop (item_name, description, price, item_type, icon_url, effect) VALUES
+  ('復活の護符', 'ラン中に一度だけ、倒れてもその場で復活する', 300, 'consumable', '/assets/images/potion.png', 'revive'),
+  ('経験値ブースト', 'ラン中の経験値獲得量が 50% 増加する', 150, 'consumable', '/assets/images/skills/exp.png', 'exp_boost'),
+  ('コインダブラー', 'ラン終了時に獲得するコインが 2 倍になる', 200, 'consumable', '/assets/images/skills/gold.png', 'coin_doubler');
+
+ALTER TABLE runs DROP CONSTRAINT IF EXISTS runs_status_check;
+ALTER TABLE runs ADD CONSTRAINT runs_status_check CHECK (status IN ('in_progress', 'finished', 'flagged', 'aborted'));
+
+-- ランで消費したアイテム（中止時の返却に使う）
+CREATE TABLE IF NOT EXISTS run_consumables (
+  run_id UUID NOT NULL,
+  item_id INTEGER NOT NULL,
+  effect TEXT NOT NULL,
+  quantity INTEGER NOT NULL CHECK (quantity > 0),
+  consumed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  refunded_at TIMESTAMPTZ,
+  PRIMARY KEY (run_id, item_id),
+  CONSTRAINT run_consumables_run_fk FOREIGN KEY (run_id) REFERENCES runs (id) ON DELETE CASCADE,
+  CONSTRAINT run_consumables_item_fk FOREIGN KEY (item_id) REFERENCES shop (item_id) ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS shop_audit_logs;
+ALTER TABLE shop DROP COLUMN IF EXISTS sort_order;
@@ -0,0 +1,15 @@
+ALTER TABLE shop ADD COLUMN sort_order INTEGER NOT NULL DEFAULT 0;
+
+-- 管理画面からのショップ商品の変更履歴（変更前後の商品情報を丸ごと保存する）
+CREATE TABLE IF NOT EXISTS shop_audit_logs (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  item_id INTEGER NOT NULL,
+  actor_id UUID NOT NULL,
+  action TEXT NOT NULL CHECK (action IN ('create', 'update', 'price_change', 'activate', 'deactivate', 'reorder')),
+  before JSONB,
+  after JSONB NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT shop_audit_logs_item_fk FOREIGN KEY (item_id) REFERENCES shop (item_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS shop_audit_logs_item_id_idx ON shop_audit_logs (item_id, id DESC);
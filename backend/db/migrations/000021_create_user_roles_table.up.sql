@@ -0,0 +1,11 @@
+-- ユーザーの権限（管理画面などの操作を許可する）
+-- JWTのapp_metadata.rolesに含まれる権限とあわせて判定する
+CREATE TABLE IF NOT EXISTS user_roles (
+  user_id UUID NOT NULL,
+  role TEXT NOT NULL CHECK (role IN ('admin', 'moderator')),
+  granted_by UUID,
+  granted_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (user_id, role),
+  CONSTRAINT user_roles_user_fk FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+  CONSTRAINT user_roles_granted_by_fk FOREIGN KEY (granted_by) REFERENCES users (id) ON DELETE SET NULL
+);
@@ -0,0 +1,4 @@
+DELETE FROM users WHERE is_guest;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_required;
+ALTER TABLE users ALTER COLUMN email SET NOT NULL;
+ALTER TABLE users DROP COLUMN IF EXISTS is_guest;
@@ -0,0 +1,4 @@
+-- ゲストアカウント（ログイン前にプレイできる、バックエンドが発行したトークンで認証するユーザー）
+ALTER TABLE users ADD COLUMN is_guest BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE users ALTER COLUMN email DROP NOT NULL;
+ALTER TABLE users ADD CONSTRAINT users_email_required CHECK (is_guest OR email IS NOT NULL);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS users_purge_at_idx;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_deletion_consistent;
+ALTER TABLE users DROP COLUMN IF EXISTS purge_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
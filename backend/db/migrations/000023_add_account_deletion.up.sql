@@ -0,0 +1,6 @@
+-- アカウント削除の申請（猶予期間が過ぎるとpurge_atを基準に完全に削除する）
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN purge_at TIMESTAMPTZ;
+ALTER TABLE users ADD CONSTRAINT users_deletion_consistent CHECK ((deleted_at IS NULL) = (purge_at IS NULL));
+
+CREATE INDEX IF NOT EXISTS users_purge_at_idx ON users (purge_at) WHERE purge_at IS NOT NULL;
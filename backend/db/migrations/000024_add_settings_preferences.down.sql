@@ -0,0 +1,2 @@
+ALTER TABLE settings DROP CONSTRAINT IF EXISTS settings_preferences_object;
+ALTER TABLE settings DROP COLUMN IF EXISTS preferences;
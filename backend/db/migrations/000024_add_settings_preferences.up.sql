@@ -0,0 +1,4 @@
+-- 音量以外の設定（キー割り当て・入力方法・言語・テーマなど）
+-- 項目はアプリケーション側で検証し、保存されていない項目はデフォルト値で補う
+ALTER TABLE settings ADD COLUMN preferences JSONB NOT NULL DEFAULT '{}'::jsonb;
+ALTER TABLE settings ADD CONSTRAINT settings_preferences_object CHECK (jsonb_typeof(preferences) = 'object');
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS set_settings_profiles_updated_at ON settings_profiles;
+DROP TABLE IF EXISTS settings_profiles;
@@ -0,0 +1,17 @@
+-- 端末ごとの設定（アカウントの設定のうち、端末で変えたい項目だけを上書きする）
+CREATE TABLE IF NOT EXISTS settings_profiles (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  user_id UUID NOT NULL,
+  device_id TEXT NOT NULL CHECK (char_length(device_id) BETWEEN 1 AND 64),
+  name TEXT NOT NULL CHECK (char_length(name) BETWEEN 1 AND 32),
+  overrides JSONB NOT NULL DEFAULT '{}'::jsonb CHECK (jsonb_typeof(overrides) = 'object'),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT settings_profiles_user_fk FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+  CONSTRAINT settings_profiles_user_device_unique UNIQUE (user_id, device_id)
+);
+
+CREATE TRIGGER set_settings_profiles_updated_at
+BEFORE UPDATE ON settings_profiles
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
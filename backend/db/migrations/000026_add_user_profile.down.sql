@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS users_display_name_lower_idx;
+ALTER TABLE users DROP COLUMN IF EXISTS display_name_changed_at;
+ALTER TABLE users DROP COLUMN IF EXISTS avatar;
+ALTER TABLE users DROP COLUMN IF EXISTS display_name;
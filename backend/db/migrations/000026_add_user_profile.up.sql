@@ -0,0 +1,7 @@
+-- プロフィール編集で設定する表示名とアバター（Googleアカウントの名前・アイコンとは別に管理する）
+ALTER TABLE users ADD COLUMN display_name TEXT CHECK (char_length(display_name) BETWEEN 1 AND 32);
+ALTER TABLE users ADD COLUMN avatar TEXT;
+ALTER TABLE users ADD COLUMN display_name_changed_at TIMESTAMPTZ;
+
+-- 表示名の重複確認用（大文字・小文字を区別しない）
+CREATE INDEX IF NOT EXISTS users_display_name_lower_idx ON users (lower(display_name)) WHERE display_name IS NOT NULL;
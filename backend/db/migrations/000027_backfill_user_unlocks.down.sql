@@ -0,0 +1 @@
+-- 登録した解放は通常の解放と区別できないため、取り消さない
@@ -0,0 +1,21 @@
+-- 実績・レベル条件による解放はラン終了時のみ判定するようになったため、
+-- 既に条件を満たしているユーザーの解放をまとめて登録する
+INSERT INTO user_unlocks (user_id, unlockable_id, source)
+SELECT ua.user_id, u.id, 'achievement'
+FROM unlockables u
+JOIN achievements a ON a.code = u.params->>'achievement'
+JOIN user_achievements ua ON ua.achievement_id = a.id
+WHERE u.condition_type = 'achievement' AND u.is_active
+ON CONFLICT (user_id, unlockable_id) DO NOTHING;
+
+INSERT INTO user_unlocks (user_id, unlockable_id, source)
+SELECT r.user_id, u.id, 'level'
+FROM unlockables u
+JOIN (
+  SELECT user_id, MAX(level) AS best_level
+  FROM runs
+  WHERE status = 'finished'
+  GROUP BY user_id
+) r ON r.best_level >= (u.params->>'level')::int
+WHERE u.condition_type = 'level' AND u.is_active
+ON CONFLICT (user_id, unlockable_id) DO NOTHING;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS users_guest_updated_at_idx;
@@ -0,0 +1,2 @@
+-- 利用されていないゲストの定期削除用
+CREATE INDEX IF NOT EXISTS users_guest_updated_at_idx ON users (updated_at) WHERE is_guest;
@@ -0,0 +1,4 @@
+DELETE FROM coin_transactions WHERE merged_from_user_id IS NOT NULL;
+ALTER TABLE coin_transactions DROP CONSTRAINT IF EXISTS coin_transactions_merged_from_check;
+ALTER TABLE coin_transactions DROP COLUMN IF EXISTS original_id;
+ALTER TABLE coin_transactions DROP COLUMN IF EXISTS merged_from_user_id;
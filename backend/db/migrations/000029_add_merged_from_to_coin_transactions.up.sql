@@ -0,0 +1,7 @@
+-- ゲストアカウントの引き継ぎ時に、ゲストの台帳を引き継ぎ先のユーザーに複製して残す
+-- 複製したレコードは履歴としてのみ扱い、balance_afterはゲストの残高を表す（引き継ぎ先の残高には guest_merge のレコードで反映する）
+ALTER TABLE coin_transactions ADD COLUMN merged_from_user_id UUID;
+ALTER TABLE coin_transactions ADD COLUMN original_id BIGINT;
+
+ALTER TABLE coin_transactions ADD CONSTRAINT coin_transactions_merged_from_check
+  CHECK ((merged_from_user_id IS NULL) = (original_id IS NULL));
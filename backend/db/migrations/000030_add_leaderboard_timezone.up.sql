@@ -0,0 +1,8 @@
+-- 日別・週別ランキングの期間をどのタイムゾーンで区切って集計したかを記録する
+-- 000013 の集計は Asia/Tokyo 基準のため、GAME_TIMEZONE が異なる場合は起動時にランから集計し直す
+CREATE TABLE IF NOT EXISTS leaderboard_state (
+  id SMALLINT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+  timezone TEXT NOT NULL
+);
+
+INSERT INTO leaderboard_state (id, timezone) VALUES (1, 'Asia/Tokyo') ON CONFLICT (id) DO NOTHING;
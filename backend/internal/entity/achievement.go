@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// 実績の達成条件の種類
+const (
+	AchievementRuleTotalClears         = "total_clears"           // 通算クリア回数
+	AchievementRuleTotalKills          = "total_kills"            // 通算撃破数
+	AchievementRuleTotalRuns           = "total_runs"             // 通算プレイ回数
+	AchievementRuleKillsInRun          = "kills_in_run"           // 1回のプレイでの撃破数
+	AchievementRuleReachLevel          = "reach_level"            // 1回のプレイでの到達レベル
+	AchievementRuleSurvivalTime        = "survival_time"          // 1回のプレイでの生存時間（秒）
+	AchievementRuleClearWithOnlyWeapon = "clear_with_only_weapon" // params.weapon の武器のみでのクリア回数
+)
+
+// AchievementParams 達成条件の追加パラメータ
+type AchievementParams struct {
+	Weapon string `json:"weapon,omitempty"`
+}
+
+// Achievement 実績のマスタ情報を表すドメインモデル
+type Achievement struct {
+	bun.BaseModel `bun:"table:achievements"`
+
+	ID          int               `bun:"id,pk,autoincrement" json:"id"`
+	Code        string            `bun:"code,notnull" json:"code"`
+	Name        string            `bun:"name,notnull" json:"name"`
+	Description string            `bun:"description,notnull" json:"description"`
+	RuleType    string            `bun:"rule_type,notnull" json:"ruleType"`
+	Target      int               `bun:"target,notnull" json:"target"`
+	Params      AchievementParams `bun:"params,type:jsonb,notnull" json:"params"`
+	RewardCoin  int               `bun:"reward_coin,notnull" json:"rewardCoin"`
+	SortOrder   int               `bun:"sort_order,notnull" json:"-"`
+	IsActive    bool              `bun:"is_active,notnull,default:true" json:"-"`
+	CreatedAt   time.Time         `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"-"`
+	UpdatedAt   time.Time         `bun:"updated_at,nullzero,notnull,default:current_timestamp" json:"-"`
+}
+
+// UserAchievement ユーザーが解除した実績を表すドメインモデル
+type UserAchievement struct {
+	bun.BaseModel `bun:"table:user_achievements"`
+
+	UserID        string    `bun:"user_id,pk" json:"userId"`
+	AchievementID int       `bun:"achievement_id,pk" json:"achievementId"`
+	RunID         string    `bun:"run_id,nullzero" json:"runId,omitempty"`
+	UnlockedAt    time.Time `bun:"unlocked_at,nullzero,notnull,default:current_timestamp" json:"unlockedAt"`
+}
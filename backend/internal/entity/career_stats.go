@@ -0,0 +1,17 @@
+package entity
+
+// CareerStats 保存されたランから集計したプレイヤーの通算成績
+type CareerStats struct {
+	TotalRuns         int      `bun:"total_runs" json:"totalRuns"`
+	Clears            int      `bun:"clears" json:"clears"`
+	Failures          int      `bun:"failures" json:"failures"`
+	TotalPlayTime     float64  `bun:"total_play_time" json:"totalPlayTime"` // 秒
+	TotalKills        int      `bun:"total_kills" json:"totalKills"`
+	TotalCoinsEarned  int      `bun:"total_coins_earned" json:"totalCoinsEarned"`
+	BestSurvivalTime  float64  `bun:"best_survival_time" json:"bestSurvivalTime"`
+	BestKillCount     int      `bun:"best_kill_count" json:"bestKillCount"`
+	BestLevel         int      `bun:"best_level" json:"bestLevel"`
+	FastestClear      *float64 `bun:"fastest_clear" json:"fastestClear"` // ラン開始からクリアまでの実時間（秒）
+	FavoriteWeapon    *string  `bun:"-" json:"favoriteWeapon"`
+	MostPickedPassive *string  `bun:"-" json:"mostPickedPassive"`
+}
@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// コイン増減の理由
+const (
+	CoinReasonRunReward      = "run_reward"
+	CoinReasonPurchase       = "purchase"
+	CoinReasonUnlockPurchase = "unlock_purchase"
+	CoinReasonAdminGrant     = "admin_grant"
+
+	CoinReasonAchievementReward = "achievement_reward"
+	CoinReasonMissionReward     = "mission_reward"
+
+	CoinReasonGuestMerge = "guest_merge" // ゲストアカウントから引き継いだ残高
+)
+
+// CoinTransaction コイン残高の増減履歴（追記専用の台帳）を表すドメインモデル
+type CoinTransaction struct {
+	bun.BaseModel `bun:"table:coin_transactions"`
+
+	ID           int64     `bun:"id,pk,autoincrement" json:"id"`
+	UserID       string    `bun:"user_id,notnull" json:"userId"`
+	Delta        int       `bun:"delta,notnull" json:"delta"`
+	Reason       string    `bun:"reason,notnull" json:"reason"`
+	ReferenceID  string    `bun:"reference_id,nullzero" json:"referenceId,omitempty"`
+	BalanceAfter int       `bun:"balance_after,notnull" json:"balanceAfter"`
+	CreatedAt    time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"createdAt"`
+
+	// ゲストアカウントから複製した履歴の場合のみ設定される（BalanceAfterはゲストの残高）
+	MergedFromUserID string `bun:"merged_from_user_id,nullzero" json:"mergedFromUserId,omitempty"`
+	OriginalID       int64  `bun:"original_id,nullzero" json:"originalId,omitempty"`
+}
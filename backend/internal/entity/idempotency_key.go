@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// IdempotencyKey 冪等性キーと、そのキーで処理したリクエストのレスポンスを表すドメインモデル
+type IdempotencyKey struct {
+	bun.BaseModel `bun:"table:idempotency_keys"`
+
+	UserID       string    `bun:"user_id,pk"`
+	Key          string    `bun:"idempotency_key,pk"`
+	RequestHash  string    `bun:"request_hash,notnull"`
+	StatusCode   *int      `bun:"status_code"` // nilの場合は処理中
+	ContentType  string    `bun:"content_type,nullzero"`
+	ResponseBody []byte    `bun:"response_body,type:bytea"`
+	CreatedAt    time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+}
@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// ランキングの種類
+const (
+	LeaderboardSurvival     = "survival"      // 最長生存時間
+	LeaderboardKills        = "kills"         // 最多撃破数
+	LeaderboardFastestClear = "fastest_clear" // 最速クリア（ラン開始からクリアまでの実時間）
+)
+
+// ランキングの集計期間
+const (
+	LeaderboardPeriodAll    = "all"
+	LeaderboardPeriodDaily  = "daily"
+	LeaderboardPeriodWeekly = "weekly"
+)
+
+// LeaderboardEntry ボード・期間ごとのユーザーの自己ベストを表すドメインモデル
+type LeaderboardEntry struct {
+	bun.BaseModel `bun:"table:leaderboard_entries"`
+
+	Board       string    `bun:"board,pk" json:"-"`
+	Period      string    `bun:"period,pk" json:"-"`
+	PeriodStart time.Time `bun:"period_start,pk,type:date" json:"-"`
+	UserID      string    `bun:"user_id,pk" json:"userId"`
+	RunID       string    `bun:"run_id,notnull" json:"runId"`
+	Score       float64   `bun:"score,notnull" json:"score"`
+	RankValue   float64   `bun:"rank_value,notnull" json:"-"` // 大きいほど上位
+	AchievedAt  time.Time `bun:"achieved_at,notnull" json:"achievedAt"`
+
+	// Relations
+	User *User `bun:"rel:belongs-to,join:user_id=id" json:"-"`
+}
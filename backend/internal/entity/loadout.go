@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// Loadout ラン開始前に選ぶ装備のプリセットを表すドメインモデル
+type Loadout struct {
+	bun.BaseModel `bun:"table:loadouts"`
+
+	ID          int64     `bun:"id,pk,autoincrement" json:"-"`
+	UserID      string    `bun:"user_id,notnull" json:"-"`
+	Name        string    `bun:"name,notnull" json:"name"`
+	Weapon      string    `bun:"weapon,notnull" json:"weapon"`
+	SpecialType string    `bun:"special_type,notnull" json:"specialType"`
+	Consumables []int     `bun:"consumables,type:jsonb,notnull" json:"consumables"` // 持ち込むアイテムのitem_id
+	CreatedAt   time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"createdAt"`
+	UpdatedAt   time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp" json:"updatedAt"`
+}
+
+// LoadoutSnapshot ラン開始時点の装備（runs.loadoutに保存する）
+type LoadoutSnapshot struct {
+	Name        string         `json:"name"`
+	Weapon      string         `json:"weapon"`
+	SpecialType string         `json:"specialType"`
+	Consumables []int          `json:"consumables"`
+	Upgrades    map[string]int `json:"upgrades"` // 恒久強化のSkillTypeごとのレベル
+}
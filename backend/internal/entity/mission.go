@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// ミッションの期間
+const (
+	MissionPeriodDaily  = "daily"
+	MissionPeriodWeekly = "weekly"
+)
+
+// ミッションの目標の種類
+const (
+	MissionObjectiveKillCount    = "kill_count"    // 期間内の合計撃破数
+	MissionObjectiveSurvivalTime = "survival_time" // 1回のプレイでの生存時間（秒）
+	MissionObjectiveClears       = "clears"        // 期間内のクリア回数
+	MissionObjectiveRuns         = "runs"          // 期間内のプレイ回数
+)
+
+// MissionParams 対象となるランの条件（未指定の項目は条件なし）
+type MissionParams struct {
+	SpecialType string `json:"specialType,omitempty"` // 装備していた必殺技
+	Weapon      string `json:"weapon,omitempty"`      // 所持していた武器
+}
+
+// MissionTemplate ミッションの元になるテンプレートを表すドメインモデル
+type MissionTemplate struct {
+	bun.BaseModel `bun:"table:mission_templates"`
+
+	ID          int           `bun:"id,pk,autoincrement" json:"id"`
+	Code        string        `bun:"code,notnull" json:"code"`
+	Period      string        `bun:"period,notnull" json:"period"`
+	Title       string        `bun:"title,notnull" json:"title"`
+	Description string        `bun:"description,notnull" json:"description"`
+	Objective   string        `bun:"objective,notnull" json:"objective"`
+	Target      int           `bun:"target,notnull" json:"target"`
+	Params      MissionParams `bun:"params,type:jsonb,notnull" json:"params"`
+	RewardCoin  int           `bun:"reward_coin,notnull" json:"rewardCoin"`
+	Weight      int           `bun:"weight,notnull,default:1" json:"-"`
+	IsActive    bool          `bun:"is_active,notnull,default:true" json:"-"`
+	CreatedAt   time.Time     `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"-"`
+	UpdatedAt   time.Time     `bun:"updated_at,nullzero,notnull,default:current_timestamp" json:"-"`
+}
+
+// UserMission ユーザーに割り当てられた期間ごとのミッションを表すドメインモデル
+type UserMission struct {
+	bun.BaseModel `bun:"table:user_missions"`
+
+	ID          int64      `bun:"id,pk,autoincrement" json:"id"`
+	UserID      string     `bun:"user_id,notnull" json:"-"`
+	TemplateID  int        `bun:"template_id,notnull" json:"-"`
+	Period      string     `bun:"period,notnull" json:"period"`
+	PeriodStart time.Time  `bun:"period_start,notnull,type:date" json:"-"`
+	ExpiresAt   time.Time  `bun:"expires_at,notnull" json:"expiresAt"`
+	Progress    int        `bun:"progress,notnull" json:"progress"`
+	Target      int        `bun:"target,notnull" json:"target"`
+	RewardCoin  int        `bun:"reward_coin,notnull" json:"rewardCoin"`
+	CompletedAt *time.Time `bun:"completed_at" json:"completedAt"`
+	ClaimedAt   *time.Time `bun:"claimed_at" json:"claimedAt"`
+	CreatedAt   time.Time  `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"-"`
+	UpdatedAt   time.Time  `bun:"updated_at,nullzero,notnull,default:current_timestamp" json:"-"`
+
+	// Relations
+	Template *MissionTemplate `bun:"rel:belongs-to,join:template_id=id" json:"template,omitempty"`
+}
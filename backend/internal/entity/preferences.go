@@ -0,0 +1,139 @@
+package entity
+
+import "encoding/json"
+
+// PreferencesVersion 設定項目の構成のバージョン
+// 既存の項目の意味や形式を変える場合に上げる（項目の追加だけであれば上げなくてよい）
+const PreferencesVersion = 1
+
+// 入力方法
+const (
+	InputModeKeyboard     = "keyboard"      // キーボード
+	InputModeHandTracking = "hand_tracking" // カメラによる手の認識
+)
+
+// キー割り当ての対象となる操作
+const (
+	KeyActionMoveUp    = "moveUp"
+	KeyActionMoveDown  = "moveDown"
+	KeyActionMoveLeft  = "moveLeft"
+	KeyActionMoveRight = "moveRight"
+	KeyActionSpecial   = "special" // 必殺技
+	KeyActionPause     = "pause"
+)
+
+// 表示言語
+const (
+	LanguageJapanese = "ja"
+	LanguageEnglish  = "en"
+)
+
+// 画面のテーマ
+const (
+	ThemeLight  = "light"
+	ThemeDark   = "dark"
+	ThemeSystem = "system" // 端末の設定に合わせる
+)
+
+// プロフィールの公開範囲
+const (
+	ProfileVisibilityPublic  = "public"  // 誰でも成績・実績を見られる
+	ProfileVisibilityPrivate = "private" // 他のプレイヤーには名前とアバターのみ見せる
+)
+
+// Preferences 音量以外のユーザー設定（settings.preferencesにJSONBとして保存する）
+type Preferences struct {
+	Version           int                     `json:"version"`
+	InputMode         string                  `json:"inputMode"`
+	KeyBindings       map[string]string       `json:"keyBindings"` // 操作 -> KeyboardEvent.code（空文字は割り当てなし）
+	HandTracking      HandTrackingPreferences `json:"handTracking"`
+	Language          string                  `json:"language"`
+	Theme             string                  `json:"theme"`
+	Display           DisplayPreferences      `json:"display"`
+	ProfileVisibility string                  `json:"profileVisibility"` // 他のプレイヤーへのプロフィールの公開範囲
+}
+
+// HandTrackingPreferences 手の認識による操作の設定
+type HandTrackingPreferences struct {
+	Sensitivity float64          `json:"sensitivity"`           // 手の移動量に対するキャラクターの移動量の倍率
+	Calibration *HandCalibration `json:"calibration,omitempty"` // 未調整の場合はnil
+}
+
+// HandCalibration 手の認識の調整結果（カメラ映像の幅・高さを1とした座標）
+type HandCalibration struct {
+	CenterX float64 `json:"centerX"` // 手を置いたときに静止とみなす位置
+	CenterY float64 `json:"centerY"`
+	Range   float64 `json:"range"` // 中心から最大速度になるまでの距離
+}
+
+// DisplayPreferences 画面表示の設定
+type DisplayPreferences struct {
+	ShowFPS           bool    `json:"showFps"`
+	ShowDamageNumbers bool    `json:"showDamageNumbers"`
+	ScreenShake       bool    `json:"screenShake"`
+	UIScale           float64 `json:"uiScale"`
+}
+
+// DefaultPreferences 設定を保存していないユーザーの設定を返す
+func DefaultPreferences() Preferences {
+	return Preferences{
+		Version:   PreferencesVersion,
+		InputMode: InputModeHandTracking,
+		KeyBindings: map[string]string{
+			KeyActionMoveUp:    "KeyW",
+			KeyActionMoveDown:  "KeyS",
+			KeyActionMoveLeft:  "KeyA",
+			KeyActionMoveRight: "KeyD",
+			KeyActionSpecial:   "Space",
+			KeyActionPause:     "Escape",
+		},
+		HandTracking: HandTrackingPreferences{
+			Sensitivity: 1.0,
+		},
+		Language: LanguageJapanese,
+		Theme:    ThemeLight,
+		Display: DisplayPreferences{
+			ShowDamageNumbers: true,
+			ScreenShake:       true,
+			UIScale:           1.0,
+		},
+		ProfileVisibility: ProfileVisibilityPublic,
+	}
+}
+
+// UnmarshalJSON 保存されていない項目はデフォルト値で補う
+// 後から追加した項目もマイグレーションなしで読み込めるようにするため
+func (p *Preferences) UnmarshalJSON(data []byte) error {
+	type plain Preferences
+	v := plain(DefaultPreferences())
+	// mapは既存のキーに追加で読み込まれるため、デフォルトの割り当てを残さない
+	v.KeyBindings = nil
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*p = Preferences(v)
+	p.FillDefaultKeyBindings()
+	return nil
+}
+
+// FillDefaultKeyBindings 割り当てが保存されていない操作にデフォルトのキーを割り当てる
+// デフォルトのキーが他の操作に使われている場合は割り当てなしとする
+func (p *Preferences) FillDefaultKeyBindings() {
+	if p.KeyBindings == nil {
+		p.KeyBindings = map[string]string{}
+	}
+	used := make(map[string]bool, len(p.KeyBindings))
+	for _, code := range p.KeyBindings {
+		used[code] = true
+	}
+	for action, code := range DefaultPreferences().KeyBindings {
+		if _, ok := p.KeyBindings[action]; ok {
+			continue
+		}
+		if used[code] {
+			code = ""
+		}
+		p.KeyBindings[action] = code
+		used[code] = true
+	}
+}
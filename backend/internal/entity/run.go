@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// ランの状態
+const (
+	RunStatusInProgress = "in_progress"
+	RunStatusFinished   = "finished"
+	RunStatusFlagged    = "flagged" // 不審な結果のため報酬付与を保留中
+	RunStatusAborted    = "aborted" // 開始直後に中止された（消費アイテムは返却済み）
+)
+
+// RunSkill ラン終了時点で所持していた武器・パッシブスキルとそのレベル
+// typeにはクライアントのSkillTypeの値（GUN, ATTACK_UPなど）が入る
+type RunSkill struct {
+	Type  string `json:"type"`
+	Level int    `json:"level"`
+}
+
+// Run 1回のプレイ（ラン）の記録を表すドメインモデル
+type Run struct {
+	bun.BaseModel `bun:"table:runs"`
+
+	ID           string     `bun:"id,pk,type:uuid,default:gen_random_uuid()" json:"id"`
+	UserID       string     `bun:"user_id,notnull" json:"userId"`
+	Nonce        string     `bun:"nonce,notnull" json:"nonce,omitempty"`
+	Status       string     `bun:"status,notnull,default:'in_progress'" json:"status"`
+	StartedAt    time.Time  `bun:"started_at,nullzero,notnull,default:current_timestamp" json:"startedAt"`
+	FinishedAt   *time.Time `bun:"finished_at" json:"finishedAt"`
+	SurvivalTime float64    `bun:"survival_time,notnull" json:"time"`
+	KillCount    int        `bun:"kill_count,notnull" json:"killCount"`
+	Level        int        `bun:"level,notnull,default:1" json:"level"`
+	Coins        int        `bun:"coins,notnull" json:"coins"`
+	Weapons      []RunSkill `bun:"weapons,type:jsonb,notnull" json:"weapons"`
+	Passives     []RunSkill `bun:"passives,type:jsonb,notnull" json:"passives"`
+	SpecialType  string     `bun:"special_type,nullzero" json:"specialType,omitempty"`
+	Cleared      bool       `bun:"cleared,notnull" json:"cleared"`
+	CoinReward   int        `bun:"coin_reward,notnull" json:"coinReward"`
+	CreatedAt    time.Time  `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"createdAt"`
+	UpdatedAt    time.Time  `bun:"updated_at,nullzero,notnull,default:current_timestamp" json:"updatedAt"`
+
+	// ラン開始時に装備を選択した場合のみ設定される
+	Loadout *LoadoutSnapshot `bun:"loadout,type:jsonb" json:"loadout,omitempty"`
+}
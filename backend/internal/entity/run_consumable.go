@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// 消費アイテムの効果
+const (
+	ConsumableEffectRevive      = "revive"       // ラン中に一度だけ復活する（クライアントで処理）
+	ConsumableEffectExpBoost    = "exp_boost"    // 経験値獲得量の増加（クライアントで処理）
+	ConsumableEffectCoinDoubler = "coin_doubler" // ラン終了時の獲得コインが2倍になる
+)
+
+// RunConsumable ランの開始時に消費したアイテムを表すドメインモデル
+type RunConsumable struct {
+	bun.BaseModel `bun:"table:run_consumables"`
+
+	RunID      string     `bun:"run_id,pk,type:uuid" json:"-"`
+	ItemID     int        `bun:"item_id,pk" json:"itemId"`
+	Effect     string     `bun:"effect,notnull" json:"effect"`
+	Quantity   int        `bun:"quantity,notnull" json:"quantity"`
+	ConsumedAt time.Time  `bun:"consumed_at,nullzero,notnull,default:current_timestamp" json:"consumedAt"`
+	RefundedAt *time.Time `bun:"refunded_at" json:"refundedAt"`
+}
@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// 審査の状態
+const (
+	RunReviewStatusPending  = "pending"
+	RunReviewStatusApproved = "approved"
+	RunReviewStatusRejected = "rejected"
+)
+
+// RunReview 不審な結果として報酬付与を保留したランの審査情報を表すドメインモデル
+type RunReview struct {
+	bun.BaseModel `bun:"table:run_reviews"`
+
+	RunID     string    `bun:"run_id,pk,type:uuid" json:"runId"`
+	UserID    string    `bun:"user_id,notnull" json:"userId"`
+	Reasons   []string  `bun:"reasons,type:jsonb,notnull" json:"reasons"`
+	Status    string    `bun:"status,notnull,default:'pending'" json:"status"`
+	CreatedAt time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"createdAt"`
+	UpdatedAt time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp" json:"updatedAt"`
+}
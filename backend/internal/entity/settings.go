@@ -15,4 +15,7 @@ type Settings struct {
 	SEVolume  int       `bun:"se_volume,notnull,default:100" json:"seVolume"`
 	CreatedAt time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"createdAt"`
 	UpdatedAt time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp" json:"updatedAt"`
+
+	// 音量以外の設定（項目を追加してもマイグレーションは不要）
+	Preferences Preferences `bun:"preferences,type:jsonb,notnull" json:"preferences"`
 }
@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// SettingsProfile 端末ごとの設定を表すドメインモデル
+// Overridesはアカウントの設定（bgmVolume・seVolume・preferences）のうち上書きする項目だけを持つ
+type SettingsProfile struct {
+	bun.BaseModel `bun:"table:settings_profiles"`
+
+	ID        int64          `bun:"id,pk,autoincrement" json:"-"`
+	UserID    string         `bun:"user_id,notnull" json:"-"`
+	DeviceID  string         `bun:"device_id,notnull" json:"deviceId"` // クライアントが端末ごとに生成する識別子
+	Name      string         `bun:"name,notnull" json:"name"`
+	Overrides map[string]any `bun:"overrides,type:jsonb,notnull" json:"overrides"`
+	CreatedAt time.Time      `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"createdAt"`
+	UpdatedAt time.Time      `bun:"updated_at,nullzero,notnull,default:current_timestamp" json:"updatedAt"`
+}
@@ -17,6 +17,33 @@ type Shop struct {
 	ItemType    string    `bun:"item_type,notnull" json:"itemType"`
 	IconURL     string    `bun:"icon_url,notnull" json:"iconUrl"`
 	IsActive    bool      `bun:"is_active,notnull,default:true" json:"isActive"`
+	SortOrder   int       `bun:"sort_order,notnull" json:"sortOrder"`
 	CreatedAt   time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"createdAt"`
 	UpdatedAt   time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp" json:"updatedAt"`
+
+	// 恒久強化の場合のみ設定される（所持数を現在のレベルとして扱う）
+	SkillType   string `bun:"skill_type,nullzero" json:"skillType,omitempty"`
+	MaxLevel    int    `bun:"max_level,nullzero" json:"maxLevel,omitempty"`
+	LevelPrices []int  `bun:"level_prices,type:jsonb,nullzero" json:"levelPrices,omitempty"`
+
+	// 消費アイテムの場合のみ設定される（ConsumableEffect*）
+	Effect string `bun:"effect,nullzero" json:"effect,omitempty"`
+}
+
+// IsUpgrade レベル制の恒久強化かどうか
+func (s *Shop) IsUpgrade() bool {
+	return s.SkillType != ""
+}
+
+// IsConsumable ラン開始時に消費するアイテムかどうか
+func (s *Shop) IsConsumable() bool {
+	return s.Effect != ""
+}
+
+// PriceForLevel 指定したレベルへ強化するときの価格（範囲外の場合はfalse）
+func (s *Shop) PriceForLevel(level int) (int, bool) {
+	if level < 1 || level > s.MaxLevel || level > len(s.LevelPrices) {
+		return 0, false
+	}
+	return s.LevelPrices[level-1], true
 }
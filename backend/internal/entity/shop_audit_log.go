@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// ショップ商品の変更操作の種類
+const (
+	ShopAuditActionCreate      = "create"
+	ShopAuditActionUpdate      = "update"
+	ShopAuditActionPriceChange = "price_change"
+	ShopAuditActionActivate    = "activate"
+	ShopAuditActionDeactivate  = "deactivate"
+	ShopAuditActionReorder     = "reorder"
+)
+
+// ShopAuditLog ショップ商品の変更履歴を表すドメインモデル
+type ShopAuditLog struct {
+	bun.BaseModel `bun:"table:shop_audit_logs"`
+
+	ID        int64     `bun:"id,pk,autoincrement" json:"id"`
+	ItemID    int       `bun:"item_id,notnull" json:"itemId"`
+	ActorID   string    `bun:"actor_id,notnull" json:"actorId"`
+	Action    string    `bun:"action,notnull" json:"action"`
+	Before    *Shop     `bun:"before,type:jsonb" json:"before"`
+	After     *Shop     `bun:"after,type:jsonb,notnull" json:"after"`
+	CreatedAt time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"createdAt"`
+}
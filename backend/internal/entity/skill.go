@@ -0,0 +1,48 @@
+package entity
+
+// スキルの種類（クライアントのSkillTypeと一致させる）
+const (
+	SkillAttackUp           = "ATTACK_UP"
+	SkillDefenseUp          = "DEFENSE_UP"
+	SkillSpeedUp            = "SPEED_UP"
+	SkillCooldownDown       = "COOLDOWN_DOWN"
+	SkillMultiShot          = "MULTI_SHOT"
+	SkillMagnetUp           = "MAGNET_UP"
+	SkillExpUp              = "EXP_UP"
+	SkillHeal               = "HEAL"
+	SkillGetCoin            = "GET_COIN"
+	SkillSpecialCooldownCut = "SPECIAL_COOLDOWN_CUT"
+
+	// Weapons
+	SkillGun   = "GUN"
+	SkillSword = "SWORD"
+)
+
+// 必殺技の種類（クライアントのSpecialSkillTypeと一致させる）
+const (
+	SpecialMuryoKusho = "MURYO_KUSHO"
+	SpecialKon        = "KON"
+)
+
+// WeaponMaxLevels 武器ごとの最大レベル
+var WeaponMaxLevels = map[string]int{
+	SkillGun:   5,
+	SkillSword: 5,
+}
+
+// PassiveMaxLevels パッシブスキルごとの最大レベル（HEAL, GET_COINは即時効果のため含まない）
+var PassiveMaxLevels = map[string]int{
+	SkillAttackUp:           5,
+	SkillDefenseUp:          5,
+	SkillSpeedUp:            5,
+	SkillCooldownDown:       5,
+	SkillMultiShot:          1,
+	SkillMagnetUp:           5,
+	SkillExpUp:              5,
+	SkillSpecialCooldownCut: 5,
+}
+
+// IsSpecialSkill 必殺技の種類として有効かどうか
+func IsSpecialSkill(t string) bool {
+	return t == SpecialMuryoKusho || t == SpecialKon
+}
@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// 解放対象の種類
+const (
+	UnlockableKindWeapon  = "weapon"
+	UnlockableKindSpecial = "special"
+)
+
+// 解放条件の種類
+const (
+	UnlockConditionDefault     = "default"     // 最初から解放済み
+	UnlockConditionCoin        = "coin"        // コインで購入
+	UnlockConditionAchievement = "achievement" // params.achievement の実績を解除
+	UnlockConditionLevel       = "level"       // 1回のプレイで params.level に到達
+)
+
+// 解放の経緯（user_unlocks.source）
+const (
+	UnlockSourceCoin        = "coin"
+	UnlockSourceAchievement = "achievement"
+	UnlockSourceLevel       = "level"
+	UnlockSourceGrant       = "grant"
+)
+
+// UnlockParams 解放条件の追加パラメータ
+type UnlockParams struct {
+	Achievement string `json:"achievement,omitempty"`
+	Level       int    `json:"level,omitempty"`
+}
+
+// Unlockable 解放できる武器・必殺技のマスタ情報を表すドメインモデル
+type Unlockable struct {
+	bun.BaseModel `bun:"table:unlockables"`
+
+	ID            int          `bun:"id,pk,autoincrement" json:"id"`
+	Code          string       `bun:"code,notnull" json:"code"`
+	Kind          string       `bun:"kind,notnull" json:"kind"`
+	Name          string       `bun:"name,notnull" json:"name"`
+	Description   string       `bun:"description,notnull" json:"description"`
+	ConditionType string       `bun:"condition_type,notnull" json:"conditionType"`
+	Price         int          `bun:"price,notnull" json:"price"`
+	Params        UnlockParams `bun:"params,type:jsonb,notnull" json:"params"`
+	SortOrder     int          `bun:"sort_order,notnull" json:"-"`
+	IsActive      bool         `bun:"is_active,notnull,default:true" json:"-"`
+	CreatedAt     time.Time    `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"-"`
+	UpdatedAt     time.Time    `bun:"updated_at,nullzero,notnull,default:current_timestamp" json:"-"`
+}
+
+// UserUnlock ユーザーが解放した武器・必殺技を表すドメインモデル
+type UserUnlock struct {
+	bun.BaseModel `bun:"table:user_unlocks"`
+
+	UserID       string    `bun:"user_id,pk" json:"userId"`
+	UnlockableID int       `bun:"unlockable_id,pk" json:"unlockableId"`
+	Source       string    `bun:"source,notnull" json:"source"`
+	UnlockedAt   time.Time `bun:"unlocked_at,nullzero,notnull,default:current_timestamp" json:"unlockedAt"`
+}
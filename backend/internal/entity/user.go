@@ -10,11 +10,47 @@ import (
 type User struct {
 	bun.BaseModel `bun:"table:users"`
 
-	ID        string    `bun:",pk" json:"id"`        // Supabase Auth ID
-	Email     string    `bun:",notnull" json:"email"`
+	ID        string    `bun:",pk" json:"id"`          // Supabase Auth ID
+	Email     string    `bun:",nullzero" json:"email"` // ゲストの場合は空
 	Name      string    `bun:",notnull" json:"name"`
 	AvatarURL string    `bun:",nullzero" json:"avatarUrl"` // GoogleアイコンURL
 	Coin      int       `bun:",notnull,default:0" json:"coin"`
+	IsGuest   bool      `bun:",notnull,default:false" json:"isGuest"`
 	CreatedAt time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"createdAt"`
 	UpdatedAt time.Time `bun:",nullzero,notnull,default:current_timestamp" json:"updatedAt"`
+
+	// 削除を申請したアカウントの場合のみ設定される（PurgeAtを過ぎると完全に削除される）
+	DeletedAt *time.Time `bun:",nullzero" json:"deletedAt,omitempty"`
+	PurgeAt   *time.Time `bun:",nullzero" json:"purgeAt,omitempty"`
+
+	// プロフィール編集で設定する表示名とアバター（表示名が未設定の場合はPublicNameの名前、アバターが未設定の場合はGoogleアカウントのアイコンを使う）
+	DisplayName          string     `bun:",nullzero" json:"displayName,omitempty"`
+	Avatar               string     `bun:",nullzero" json:"avatar,omitempty"` // AvatarGoogleまたはAvatarPresetsのいずれか
+	DisplayNameChangedAt *time.Time `bun:",nullzero" json:"displayNameChangedAt,omitempty"`
+}
+
+// AvatarGoogle Googleアカウントのアイコンをアバターとして使う
+const AvatarGoogle = "google"
+
+// AvatarPresets ゲーム内で選べるアバター（クライアントの画像と一致させる）
+var AvatarPresets = []string{"knight", "mage", "archer", "rogue", "priest", "ninja"}
+
+// PublicName ランキングなど他のプレイヤーに見せる名前
+// Googleアカウントの名前は本名の場合があるため、表示名が未設定の場合はIDから作った名前を使う（ゲストは作成時に付けた名前）
+func (u *User) PublicName() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	if u.IsGuest {
+		return u.Name
+	}
+	return "Player-" + u.ID[:min(8, len(u.ID))]
+}
+
+// PublicAvatarURL 他のプレイヤーに見せるアイコンのURL（ゲーム内のアバターを選んでいる場合は空）
+func (u *User) PublicAvatarURL() string {
+	if u.Avatar == "" || u.Avatar == AvatarGoogle {
+		return u.AvatarURL
+	}
+	return ""
 }
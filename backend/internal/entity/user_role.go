@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// ユーザーの権限
+const (
+	RoleAdmin     = "admin"     // ショップ商品の管理などすべての管理操作
+	RoleModerator = "moderator" // 管理画面の閲覧
+)
+
+// UserRole ユーザーに付与された権限を表すドメインモデル
+type UserRole struct {
+	bun.BaseModel `bun:"table:user_roles"`
+
+	UserID    string    `bun:"user_id,pk" json:"userId"`
+	Role      string    `bun:"role,pk" json:"role"`
+	GrantedBy string    `bun:"granted_by,nullzero" json:"grantedBy,omitempty"`
+	GrantedAt time.Time `bun:"granted_at,nullzero,notnull,default:current_timestamp" json:"grantedAt"`
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type AccountHandler struct {
+	service *service.AccountService
+}
+
+func NewAccountHandler(service *service.AccountService) *AccountHandler {
+	return &AccountHandler{service: service}
+}
+
+// DeleteMe アカウントの削除を申請する（猶予期間が過ぎると完全に削除される）
+// DELETE /api/v1/users/me
+func (h *AccountHandler) DeleteMe(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	user, err := h.service.RequestDeletion(c.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found"})
+		}
+		log.Printf("DeleteMe Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusAccepted, user)
+}
+
+// RestoreMe 猶予期間内のアカウントの削除申請を取り消す
+// POST /api/v1/users/me/restore
+func (h *AccountHandler) RestoreMe(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	user, err := h.service.Restore(c.Request().Context(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found"})
+		case errors.Is(err, service.ErrAccountNotScheduledForDeletion):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "account is not scheduled for deletion"})
+		}
+		log.Printf("RestoreMe Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+// ExportMe ログインユーザーの全データをJSONファイルとしてダウンロードする
+// GET /api/v1/users/me/export
+func (h *AccountHandler) ExportMe(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	res := c.Response()
+	filename := fmt.Sprintf("tri-survivor-export-%s.json", time.Now().UTC().Format(time.DateOnly))
+	res.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+
+	err := h.service.Export(c.Request().Context(), userID, res)
+	if err == nil {
+		return nil
+	}
+	// 書き出しを始めた後はステータスを変更できないため、ログに残して打ち切る
+	if res.Committed {
+		log.Printf("ExportMe Error (after response started): %v", err)
+		return nil
+	}
+	res.Header().Del(echo.HeaderContentDisposition)
+	if errors.Is(err, service.ErrUserNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found"})
+	}
+	log.Printf("ExportMe Error: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type AchievementHandler struct {
+	service *service.AchievementService
+}
+
+func NewAchievementHandler(service *service.AchievementService) *AchievementHandler {
+	return &AchievementHandler{service: service}
+}
+
+// GetAchievements 実績の一覧とログインユーザーの達成状況を取得する
+// GET /api/v1/achievements
+func (h *AchievementHandler) GetAchievements(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	achievements, err := h.service.GetAchievements(c.Request().Context(), userID)
+	if err != nil {
+		log.Printf("GetAchievements Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, achievements)
+}
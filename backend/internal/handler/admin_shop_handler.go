@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type AdminShopHandler struct {
+	service *service.ShopAdminService
+}
+
+func NewAdminShopHandler(service *service.ShopAdminService) *AdminShopHandler {
+	return &AdminShopHandler{service: service}
+}
+
+type ShopItemRequest struct {
+	ItemName    string `json:"itemName"`
+	Description string `json:"description"`
+	ItemType    string `json:"itemType"`
+	IconURL     string `json:"iconUrl"`
+	Price       int    `json:"price"`
+	IsActive    *bool  `json:"isActive"` // 省略時は販売中として登録する
+	SkillType   string `json:"skillType"`
+	LevelPrices []int  `json:"levelPrices"`
+	Effect      string `json:"effect"`
+}
+
+// UpdateShopItemRequest PUT /api/admin/shop/:idで変更できる項目（価格・販売状態は専用のAPIで変更する）
+type UpdateShopItemRequest struct {
+	ItemName    string `json:"itemName"`
+	Description string `json:"description"`
+	ItemType    string `json:"itemType"`
+	IconURL     string `json:"iconUrl"`
+}
+
+// ChangePriceRequest 恒久強化以外はprice、恒久強化はlevelPricesのみを指定する
+type ChangePriceRequest struct {
+	Price       *int  `json:"price"`
+	LevelPrices []int `json:"levelPrices"`
+}
+
+type ReorderShopRequest struct {
+	ItemIDs []int `json:"itemIds"`
+}
+
+// ListItems 販売停止中のものも含めて全商品を取得する
+// GET /api/admin/shop
+func (h *AdminShopHandler) ListItems(c echo.Context) error {
+	items, err := h.service.ListItems(c.Request().Context())
+	if err != nil {
+		log.Printf("Admin ListItems Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, items)
+}
+
+// CreateItem 商品を登録する
+// POST /api/admin/shop
+func (h *AdminShopHandler) CreateItem(c echo.Context) error {
+	actorID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	req := new(ShopItemRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	item, err := h.service.CreateItem(c.Request().Context(), actorID, service.ShopItemInput{
+		ItemName:    req.ItemName,
+		Description: req.Description,
+		ItemType:    req.ItemType,
+		IconURL:     req.IconURL,
+		Price:       req.Price,
+		IsActive:    isActive,
+		SkillType:   req.SkillType,
+		LevelPrices: req.LevelPrices,
+		Effect:      req.Effect,
+	})
+	if err != nil {
+		return adminShopError(c, "CreateItem", err)
+	}
+
+	return c.JSON(http.StatusCreated, item)
+}
+
+// UpdateItem 商品の名前・説明・種類・アイコンを更新する
+// PUT /api/admin/shop/:id
+func (h *AdminShopHandler) UpdateItem(c echo.Context) error {
+	actorID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid item id"})
+	}
+
+	// 変更できない項目が含まれる場合は、保存されたと誤解されないようエラーにする
+	req := new(UpdateShopItemRequest)
+	if err := bindStrict(c, req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body: " + err.Error()})
+	}
+
+	item, err := h.service.UpdateItem(c.Request().Context(), actorID, id, service.ShopItemInput{
+		ItemName:    req.ItemName,
+		Description: req.Description,
+		ItemType:    req.ItemType,
+		IconURL:     req.IconURL,
+	})
+	if err != nil {
+		return adminShopError(c, "UpdateItem", err)
+	}
+
+	return c.JSON(http.StatusOK, item)
+}
+
+// ChangePrice 商品の価格を変更する（恒久強化の場合はlevelPricesを指定する）
+// PUT /api/admin/shop/:id/price
+func (h *AdminShopHandler) ChangePrice(c echo.Context) error {
+	actorID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid item id"})
+	}
+
+	req := new(ChangePriceRequest)
+	if err := bindStrict(c, req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body: " + err.Error()})
+	}
+
+	item, err := h.service.ChangePrice(c.Request().Context(), actorID, id, req.Price, req.LevelPrices)
+	if err != nil {
+		return adminShopError(c, "ChangePrice", err)
+	}
+
+	return c.JSON(http.StatusOK, item)
+}
+
+// ActivateItem 商品の販売を再開する
+// POST /api/admin/shop/:id/activate
+func (h *AdminShopHandler) ActivateItem(c echo.Context) error {
+	return h.setActive(c, true)
+}
+
+// DeactivateItem 商品の販売を停止する
+// POST /api/admin/shop/:id/deactivate
+func (h *AdminShopHandler) DeactivateItem(c echo.Context) error {
+	return h.setActive(c, false)
+}
+
+func (h *AdminShopHandler) setActive(c echo.Context, active bool) error {
+	actorID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid item id"})
+	}
+
+	item, err := h.service.SetActive(c.Request().Context(), actorID, id, active)
+	if err != nil {
+		return adminShopError(c, "SetActive", err)
+	}
+
+	return c.JSON(http.StatusOK, item)
+}
+
+// ReorderItems 商品の表示順を並び替える
+// PUT /api/admin/shop/order
+func (h *AdminShopHandler) ReorderItems(c echo.Context) error {
+	actorID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	req := new(ReorderShopRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	items, err := h.service.Reorder(c.Request().Context(), actorID, req.ItemIDs)
+	if err != nil {
+		return adminShopError(c, "ReorderItems", err)
+	}
+
+	return c.JSON(http.StatusOK, items)
+}
+
+// GetAuditLogs 商品の変更履歴を新しい順に取得する
+// GET /api/admin/shop/:id/audit-logs?cursor=&limit=
+func (h *AdminShopHandler) GetAuditLogs(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid item id"})
+	}
+
+	limit, err := parseLimit(c.QueryParam("limit"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+	}
+
+	page, err := h.service.GetAuditLogs(c.Request().Context(), id, c.QueryParam("cursor"), limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid cursor"})
+		}
+		log.Printf("Admin GetAuditLogs Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
+
+// bindStrict リクエストボディのJSONを読み込む（未知の項目が含まれる場合はエラー）
+func bindStrict(c echo.Context, v any) error {
+	dec := json.NewDecoder(c.Request().Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("unexpected data after JSON object")
+	}
+	return nil
+}
+
+// adminShopError 商品管理のエラーをレスポンスに変換する
+func adminShopError(c echo.Context, op string, err error) error {
+	switch {
+	case errors.Is(err, service.ErrShopItemNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "item not found"})
+	case errors.Is(err, service.ErrInvalidShopItem), errors.Is(err, service.ErrInvalidReorder):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	log.Printf("Admin %s Error: %v", op, err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	userMiddleware "github.com/RiTa-23/TRI-Survivor/backend/internal/middleware"
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type GuestHandler struct {
+	service *service.GuestService
+	tokens  *userMiddleware.GuestTokens
+}
+
+// NewGuestHandler ゲストアカウントが無効な場合、tokensはnil
+func NewGuestHandler(service *service.GuestService, tokens *userMiddleware.GuestTokens) *GuestHandler {
+	return &GuestHandler{service: service, tokens: tokens}
+}
+
+// GuestSession ゲストとしてAPIを利用するためのトークン
+type GuestSession struct {
+	Token     string       `json:"token"`
+	ExpiresAt time.Time    `json:"expiresAt"`
+	User      *entity.User `json:"user,omitempty"`
+}
+
+type MergeGuestRequest struct {
+	GuestToken string `json:"guestToken"`
+}
+
+// CreateGuest ゲストアカウントを作成し、トークンを発行する
+// POST /api/guest
+func (h *GuestHandler) CreateGuest(c echo.Context) error {
+	if h.tokens == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "guest accounts are disabled"})
+	}
+
+	user, err := h.service.CreateGuest(c.Request().Context())
+	if err != nil {
+		log.Printf("CreateGuest Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	token, expiresAt, err := h.tokens.Issue(user.ID)
+	if err != nil {
+		log.Printf("CreateGuest Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusCreated, GuestSession{Token: token, ExpiresAt: expiresAt, User: user})
+}
+
+// RefreshGuestToken 有効期限内のゲストに新しいトークンを発行する
+// POST /api/v1/users/me/guest-token
+func (h *GuestHandler) RefreshGuestToken(c echo.Context) error {
+	claims, ok := userMiddleware.ClaimsFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+	if h.tokens == nil || !claims.IsGuest {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "not a guest account"})
+	}
+
+	// 利用されていないゲストとして削除されないよう、最終利用日時を更新する
+	if err := h.service.TouchGuest(c.Request().Context(), claims.Subject); err != nil {
+		if errors.Is(err, service.ErrGuestNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "guest account not found"})
+		}
+		log.Printf("RefreshGuestToken Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	token, expiresAt, err := h.tokens.Issue(claims.Subject)
+	if err != nil {
+		log.Printf("RefreshGuestToken Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, GuestSession{Token: token, ExpiresAt: expiresAt})
+}
+
+// MergeGuest ゲストアカウントのデータをログインユーザーに引き継ぐ
+// ゲストのトークンを所持していることで、ゲストアカウントの持ち主であることを確認する
+// POST /api/v1/users/me/merge
+func (h *GuestHandler) MergeGuest(c echo.Context) error {
+	claims, ok := userMiddleware.ClaimsFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+	if claims.IsGuest {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "sign in to merge a guest account"})
+	}
+	if h.tokens == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "guest accounts are disabled"})
+	}
+
+	req := new(MergeGuestRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	if req.GuestToken == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "guestToken is required"})
+	}
+
+	guest, err := h.tokens.Verify(c.Request().Context(), req.GuestToken)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid guest token"})
+	}
+
+	result, err := h.service.MergeGuest(c.Request().Context(), claims.Subject, guest.Subject)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found"})
+		case errors.Is(err, service.ErrGuestNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "guest account not found"})
+		case errors.Is(err, service.ErrMergeToGuest):
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "sign in to merge a guest account"})
+		}
+		log.Printf("MergeGuest Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	userMiddleware "github.com/RiTa-23/TRI-Survivor/backend/internal/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+// Pinger データベースへの疎通確認
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+type HealthHandler struct {
+	db       Pinger
+	verifier userMiddleware.TokenVerifier
+}
+
+func NewHealthHandler(db Pinger, verifier userMiddleware.TokenVerifier) *HealthHandler {
+	return &HealthHandler{db: db, verifier: verifier}
+}
+
+// ReadinessCheck 依存先ごとの準備状況
+type ReadinessCheck struct {
+	Ready bool   `json:"ready"`
+	Error string `json:"error,omitempty"`
+}
+
+// ReadinessResponse リクエストを受け付けられる状態かどうか
+type ReadinessResponse struct {
+	Status string                    `json:"status"`
+	Checks map[string]ReadinessCheck `json:"checks"`
+}
+
+// Ready データベースへの接続と、認証に使う鍵の取得が完了しているかを返す
+// GET /api/ready
+func (h *HealthHandler) Ready(c echo.Context) error {
+	res := ReadinessResponse{Status: "ready", Checks: map[string]ReadinessCheck{}}
+	ready := true
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 2*time.Second)
+	defer cancel()
+	if err := h.db.PingContext(ctx); err != nil {
+		log.Printf("Readiness DB Error: %v", err)
+		res.Checks["database"] = ReadinessCheck{Error: "database unreachable"}
+		ready = false
+	} else {
+		res.Checks["database"] = ReadinessCheck{Ready: true}
+	}
+
+	status := h.verifier.Status()
+	res.Checks["auth"] = ReadinessCheck{Ready: status.Ready, Error: status.Error}
+	if !status.Ready {
+		ready = false
+		c.Response().Header().Set("Retry-After", userMiddleware.RetryAfterSeconds(status.RetryAfter))
+	}
+
+	if !ready {
+		res.Status = "unavailable"
+		return c.JSON(http.StatusServiceUnavailable, res)
+	}
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type LeaderboardHandler struct {
+	service *service.LeaderboardService
+}
+
+func NewLeaderboardHandler(service *service.LeaderboardService) *LeaderboardHandler {
+	return &LeaderboardHandler{service: service}
+}
+
+// GetLeaderboard ランキングを取得する
+// GET /api/v1/leaderboards/:board?period=all|daily|weekly&limit=
+// board: survival（最長生存時間）, kills（最多撃破数）, fastest_clear（最速クリア）
+func (h *LeaderboardHandler) GetLeaderboard(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	period := c.QueryParam("period")
+	if period == "" {
+		period = entity.LeaderboardPeriodAll
+	}
+
+	limit, err := parseLimit(c.QueryParam("limit"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+	}
+
+	lb, err := h.service.GetLeaderboard(c.Request().Context(), userID, c.Param("board"), period, limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnknownLeaderboard):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "leaderboard not found"})
+		case errors.Is(err, service.ErrUnknownPeriod):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid period"})
+		}
+		log.Printf("GetLeaderboard Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, lb)
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// maxLoadoutNameLength 装備プリセット名の最大文字数
+const maxLoadoutNameLength = 32
+
+type LoadoutHandler struct {
+	service *service.LoadoutService
+}
+
+func NewLoadoutHandler(service *service.LoadoutService) *LoadoutHandler {
+	return &LoadoutHandler{service: service}
+}
+
+type SaveLoadoutRequest struct {
+	Weapon      string `json:"weapon"`
+	SpecialType string `json:"specialType"`
+	Consumables []int  `json:"consumables"`
+}
+
+// GetMyLoadout ログインユーザーの恒久強化と、ラン開始時のステータス補正を取得する
+// GET /api/v1/users/me/loadout
+func (h *LoadoutHandler) GetMyLoadout(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	loadout, err := h.service.GetLoadout(c.Request().Context(), userID)
+	if err != nil {
+		log.Printf("GetMyLoadout Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, loadout)
+}
+
+// GetMyLoadouts ログインユーザーの装備プリセット一覧を取得する
+// GET /api/v1/users/me/loadouts
+func (h *LoadoutHandler) GetMyLoadouts(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	loadouts, err := h.service.ListPresets(c.Request().Context(), userID)
+	if err != nil {
+		log.Printf("GetMyLoadouts Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, loadouts)
+}
+
+// SaveMyLoadout 装備プリセットを作成・上書きする
+// PUT /api/v1/users/me/loadouts/:name
+func (h *LoadoutHandler) SaveMyLoadout(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	name, ok := loadoutNameParam(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid loadout name"})
+	}
+
+	req := new(SaveLoadoutRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	if req.Weapon == "" || req.SpecialType == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "weapon and specialType are required"})
+	}
+	if req.Consumables == nil {
+		req.Consumables = []int{}
+	}
+
+	loadout, err := h.service.SavePreset(c.Request().Context(), &entity.Loadout{
+		UserID:      userID,
+		Name:        name,
+		Weapon:      req.Weapon,
+		SpecialType: req.SpecialType,
+		Consumables: req.Consumables,
+	})
+	if err != nil {
+		if status, body, ok := loadoutErrorResponse(err); ok {
+			return c.JSON(status, body)
+		}
+		log.Printf("SaveMyLoadout Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, loadout)
+}
+
+// DeleteMyLoadout 装備プリセットを削除する
+// DELETE /api/v1/users/me/loadouts/:name
+func (h *LoadoutHandler) DeleteMyLoadout(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	name, ok := loadoutNameParam(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid loadout name"})
+	}
+
+	if err := h.service.DeletePreset(c.Request().Context(), userID, name); err != nil {
+		if status, body, ok := loadoutErrorResponse(err); ok {
+			return c.JSON(status, body)
+		}
+		log.Printf("DeleteMyLoadout Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// loadoutNameParam パスパラメータからプリセット名を取り出す（URLエンコードされた名前に対応）
+func loadoutNameParam(c echo.Context) (string, bool) {
+	name, err := url.PathUnescape(c.Param("name"))
+	if err != nil {
+		return "", false
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxLoadoutNameLength {
+		return "", false
+	}
+	return name, true
+}
+
+// loadoutErrorResponse 装備の検証エラーをレスポンスに変換する（該当しないエラーの場合はfalse）
+func loadoutErrorResponse(err error) (int, map[string]string, bool) {
+	switch {
+	case errors.Is(err, service.ErrLoadoutNotFound):
+		return http.StatusNotFound, map[string]string{"error": "loadout not found", "code": "LOADOUT_NOT_FOUND"}, true
+	case errors.Is(err, service.ErrLoadoutLimitReached):
+		return http.StatusConflict, map[string]string{"error": "loadout preset limit reached", "code": "LOADOUT_LIMIT_REACHED"}, true
+	case errors.Is(err, service.ErrLoadoutWeaponUnavailable):
+		return http.StatusUnprocessableEntity, map[string]string{"error": "weapon is not unlocked", "code": "WEAPON_LOCKED"}, true
+	case errors.Is(err, service.ErrLoadoutSpecialUnavailable):
+		return http.StatusUnprocessableEntity, map[string]string{"error": "special skill is not unlocked", "code": "SPECIAL_LOCKED"}, true
+	case errors.Is(err, service.ErrLoadoutTooManyItems):
+		return http.StatusUnprocessableEntity, map[string]string{"error": "too many consumables", "code": "TOO_MANY_ITEMS"}, true
+	case errors.Is(err, service.ErrLoadoutItemNotOwned), errors.Is(err, service.ErrInsufficientItems):
+		return http.StatusUnprocessableEntity, map[string]string{"error": "consumable is not owned", "code": "ITEM_NOT_OWNED"}, true
+	case errors.Is(err, service.ErrItemNotConsumable):
+		return http.StatusUnprocessableEntity, map[string]string{"error": "item is not consumable", "code": "ITEM_NOT_CONSUMABLE"}, true
+	}
+	return 0, nil, false
+}
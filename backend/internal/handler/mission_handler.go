@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type MissionHandler struct {
+	service *service.MissionService
+}
+
+func NewMissionHandler(service *service.MissionService) *MissionHandler {
+	return &MissionHandler{service: service}
+}
+
+// GetMissions ログインユーザーの現在のデイリー・ウィークリーミッションを取得する
+// GET /api/v1/missions
+func (h *MissionHandler) GetMissions(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	board, err := h.service.GetMissions(c.Request().Context(), userID)
+	if err != nil {
+		log.Printf("GetMissions Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, board)
+}
+
+// ClaimMission 達成したミッションの報酬を受け取る
+// POST /api/v1/missions/:id/claim
+func (h *MissionHandler) ClaimMission(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	missionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || missionID <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid mission id"})
+	}
+
+	mission, err := h.service.ClaimReward(c.Request().Context(), userID, missionID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrMissionNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "mission not found"})
+		case errors.Is(err, service.ErrMissionNotCompleted):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "mission not completed"})
+		case errors.Is(err, service.ErrMissionAlreadyClaimed):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "mission reward already claimed"})
+		case errors.Is(err, service.ErrMissionExpired):
+			return c.JSON(http.StatusGone, map[string]string{"error": "mission expired"})
+		case errors.Is(err, service.ErrUserNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found"})
+		}
+		log.Printf("ClaimMission Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, mission)
+}
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// parseLimit クエリパラメータの件数指定を解釈する（未指定の場合はデフォルト値）
+func parseLimit(param string) (int, error) {
+	if param == "" {
+		return DefaultPageLimit, nil
+	}
+	limit, err := strconv.Atoi(param)
+	if err != nil || limit <= 0 || limit > MaxPageLimit {
+		return 0, errors.New("invalid limit")
+	}
+	return limit, nil
+}
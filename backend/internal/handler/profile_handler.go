@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	userMiddleware "github.com/RiTa-23/TRI-Survivor/backend/internal/middleware"
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type ProfileHandler struct {
+	service *service.ProfileService
+}
+
+func NewProfileHandler(service *service.ProfileService) *ProfileHandler {
+	return &ProfileHandler{service: service}
+}
+
+// UpdateProfileRequest 省略した項目は変更しない。空文字を指定すると表示名は未設定に、アバターはGoogleアカウントのアイコンに戻す
+type UpdateProfileRequest struct {
+	DisplayName *string `json:"displayName"`
+	Avatar      *string `json:"avatar"`
+}
+
+// UpdateMe ログインユーザーの表示名とアバターを変更する
+// PATCH /api/v1/users/me
+func (h *ProfileHandler) UpdateMe(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	req := new(UpdateProfileRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	if req.DisplayName == nil && req.Avatar == nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "displayName or avatar is required"})
+	}
+
+	user, err := h.service.UpdateProfile(c.Request().Context(), userID, service.ProfileUpdate{
+		DisplayName: req.DisplayName,
+		Avatar:      req.Avatar,
+	})
+	if err != nil {
+		var cooldown *service.RenameCooldownError
+		switch {
+		case errors.As(err, &cooldown):
+			c.Response().Header().Set("Retry-After", userMiddleware.RetryAfterSeconds(time.Until(cooldown.AvailableAt)))
+			return c.JSON(http.StatusTooManyRequests, map[string]string{
+				"error":       "display name was changed recently",
+				"code":        "RENAME_COOLDOWN",
+				"availableAt": cooldown.AvailableAt.Format(time.RFC3339),
+			})
+		case errors.Is(err, service.ErrInvalidDisplayName), errors.Is(err, service.ErrInvalidAvatar):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, service.ErrDisplayNameNotAllowed):
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": "display name is not allowed", "code": "DISPLAY_NAME_NOT_ALLOWED"})
+		case errors.Is(err, service.ErrDisplayNameTaken):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "display name is already taken", "code": "DISPLAY_NAME_TAKEN"})
+		case errors.Is(err, service.ErrUserNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found"})
+		}
+		log.Printf("UpdateMe Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+// GetPlayer プレイヤーの公開プロフィールを取得する
+// GET /api/v1/players/:id
+func (h *ProfileHandler) GetPlayer(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	playerID := c.Param("id")
+	if !uuidPattern.MatchString(playerID) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid player id"})
+	}
+
+	profile, err := h.service.GetPlayer(c.Request().Context(), userID, playerID)
+	if err != nil {
+		if errors.Is(err, service.ErrPlayerNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "player not found"})
+		}
+		log.Printf("GetPlayer Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, profile)
+}
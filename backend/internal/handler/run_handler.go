@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/repository"
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// maxRunCoins 1回のランで獲得できるコインの上限
+const maxRunCoins = 1000000
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+type RunHandler struct {
+	service  *service.RunService
+	calendar *service.GameCalendar
+}
+
+func NewRunHandler(service *service.RunService, calendar *service.GameCalendar) *RunHandler {
+	return &RunHandler{service: service, calendar: calendar}
+}
+
+type StartRunRequest struct {
+	Loadout string `json:"loadout"` // 装備プリセット名（省略可）
+}
+
+type AbortRunRequest struct {
+	Nonce string `json:"nonce"`
+}
+
+type FinishRunRequest struct {
+	Nonce             string            `json:"nonce"`
+	Time              float64           `json:"time"`
+	KillCount         int               `json:"killCount"`
+	Level             int               `json:"level"`
+	Coins             int               `json:"coins"`
+	Weapons           []entity.RunSkill `json:"weapons"`
+	Passives          []entity.RunSkill `json:"passives"`
+	ActiveSpecialType string            `json:"activeSpecialType"`
+}
+
+// StartRun ランを開始する
+// POST /api/v1/runs
+func (h *RunHandler) StartRun(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	req := new(StartRunRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	run, err := h.service.StartRun(c.Request().Context(), userID, req.Loadout)
+	if err != nil {
+		if status, body, ok := loadoutErrorResponse(err); ok {
+			return c.JSON(status, body)
+		}
+		log.Printf("StartRun Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusCreated, run)
+}
+
+// FinishRun ランの結果を送信し、報酬を受け取る
+// POST /api/v1/runs/:id/finish
+func (h *RunHandler) FinishRun(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	runID := c.Param("id")
+	if !uuidPattern.MatchString(runID) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid run id"})
+	}
+
+	req := new(FinishRunRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+
+	// シンプルなバリデーション
+	if req.Nonce == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "nonce is required"})
+	}
+	if req.Time < 0 || req.KillCount < 0 || req.Level < 1 || req.Coins < 0 || req.Coins > maxRunCoins {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid run result"})
+	}
+	if req.Weapons == nil {
+		req.Weapons = []entity.RunSkill{}
+	}
+	if req.Passives == nil {
+		req.Passives = []entity.RunSkill{}
+	}
+
+	summary, err := h.service.FinishRun(c.Request().Context(), userID, runID, service.RunResult{
+		Nonce:       req.Nonce,
+		Time:        req.Time,
+		KillCount:   req.KillCount,
+		Level:       req.Level,
+		Coins:       req.Coins,
+		Weapons:     req.Weapons,
+		Passives:    req.Passives,
+		SpecialType: req.ActiveSpecialType,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRunNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "run not found"})
+		case errors.Is(err, service.ErrRunAlreadyFinished):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "run already finished"})
+		case errors.Is(err, service.ErrRunNonceMismatch):
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "invalid nonce"})
+		}
+		log.Printf("FinishRun Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, summary)
+}
+
+// AbortRun 開始直後のランを中止し、持ち込んだアイテムを返却する
+// POST /api/v1/runs/:id/abort
+func (h *RunHandler) AbortRun(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	runID := c.Param("id")
+	if !uuidPattern.MatchString(runID) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid run id"})
+	}
+
+	req := new(AbortRunRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	if req.Nonce == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "nonce is required"})
+	}
+
+	aborted, err := h.service.AbortRun(c.Request().Context(), userID, runID, req.Nonce)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRunNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "run not found"})
+		case errors.Is(err, service.ErrRunAlreadyFinished):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "run already finished"})
+		case errors.Is(err, service.ErrRunNonceMismatch):
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "invalid nonce"})
+		case errors.Is(err, service.ErrRunAbortWindowClosed):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "run can no longer be aborted"})
+		}
+		log.Printf("AbortRun Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, aborted)
+}
+
+// ListMyRuns ログインユーザーのラン履歴を新しい順に取得する
+// GET /api/v1/users/me/runs?result=cleared|failed&from=YYYY-MM-DD&to=YYYY-MM-DD&cursor=&limit=
+func (h *RunHandler) ListMyRuns(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	limit, err := parseLimit(c.QueryParam("limit"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+	}
+
+	filter := repository.RunFilter{
+		UserID: userID,
+		Limit:  limit,
+	}
+
+	switch c.QueryParam("result") {
+	case "":
+	case "cleared":
+		cleared := true
+		filter.Cleared = &cleared
+	case "failed":
+		cleared := false
+		filter.Cleared = &cleared
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "result must be cleared or failed"})
+	}
+
+	// 日付はGAME_TIMEZONE基準で解釈し、toはその日の終わりまでを含める
+	if from := c.QueryParam("from"); from != "" {
+		t, err := h.calendar.ParseDate(from)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid from date"})
+		}
+		filter.From = t
+	}
+	if to := c.QueryParam("to"); to != "" {
+		t, err := h.calendar.ParseDate(to)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid to date"})
+		}
+		filter.To = t.AddDate(0, 0, 1)
+	}
+
+	if cursor := c.QueryParam("cursor"); cursor != "" {
+		if !uuidPattern.MatchString(cursor) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid cursor"})
+		}
+		filter.BeforeID = cursor
+	}
+
+	history, err := h.service.ListRuns(c.Request().Context(), filter)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid cursor"})
+		}
+		log.Printf("ListMyRuns Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, history)
+}
+
+// GetMyStats ログインユーザーの通算成績を取得する
+// GET /api/v1/users/me/stats
+func (h *RunHandler) GetMyStats(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	stats, err := h.service.GetCareerStats(c.Request().Context(), userID)
+	if err != nil {
+		log.Printf("GetMyStats Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, stats)
+}
@@ -1,9 +1,17 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
 	"log"
+	"mime"
 	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
 
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
 	"github.com/RiTa-23/TRI-Survivor/backend/internal/service"
 	"github.com/labstack/echo/v4"
 )
@@ -16,9 +24,33 @@ func NewSettingsHandler(service *service.SettingsService) *SettingsHandler {
 	return &SettingsHandler{service: service}
 }
 
+const (
+	// HeaderETag 設定の版を表すヘッダー（更新時にIf-Matchとして送る）
+	HeaderETag    = "ETag"
+	HeaderIfMatch = "If-Match"
+
+	// MIMEMergePatchJSON JSON Merge Patch (RFC 7396) のContent-Type
+	MIMEMergePatchJSON = "application/merge-patch+json"
+)
+
+// maxSettingsPatchSize PATCHで受け付けるリクエストボディの最大サイズ
+const maxSettingsPatchSize = 64 << 10
+
+// deviceIDPattern クライアントが端末ごとに生成する識別子の形式
+var deviceIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// maxSettingsProfileNameLength 端末ごとの設定の名前の最大文字数
+const maxSettingsProfileNameLength = 32
+
+type SaveSettingsProfileRequest struct {
+	Name      string         `json:"name"`
+	Overrides map[string]any `json:"overrides"` // アカウントの設定のうち上書きする項目
+}
+
 type UpdateSettingsRequest struct {
-	BGMVolume int `json:"bgmVolume"`
-	SEVolume  int `json:"seVolume"`
+	BGMVolume   int             `json:"bgmVolume"`
+	SEVolume    int             `json:"seVolume"`
+	Preferences json.RawMessage `json:"preferences"` // 省略した場合は現在の設定を維持する
 }
 
 // GetSettings ログインユーザーの設定を取得する
@@ -35,11 +67,13 @@ func (h *SettingsHandler) GetSettings(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
 	}
 
+	c.Response().Header().Set(HeaderETag, service.SettingsETag(settings))
 	return c.JSON(http.StatusOK, settings)
 }
 
 // UpdateSettings ログインユーザーの設定を更新する
 // PUT /api/v1/settings
+// If-Matchを指定した場合、他の端末が先に更新していれば412を返す
 func (h *SettingsHandler) UpdateSettings(c echo.Context) error {
 	userID, ok := c.Get("userID").(string)
 	if !ok {
@@ -56,11 +90,174 @@ func (h *SettingsHandler) UpdateSettings(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "volume must be between 0 and 100"})
 	}
 
-	settings, err := h.service.UpdateSettings(c.Request().Context(), userID, req.BGMVolume, req.SEVolume)
+	var preferences *entity.Preferences
+	if len(req.Preferences) > 0 && string(req.Preferences) != "null" {
+		p, err := service.DecodePreferences(req.Preferences)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		preferences = p
+	}
+
+	settings, err := h.service.UpdateSettings(c.Request().Context(), userID, req.BGMVolume, req.SEVolume, preferences, c.Request().Header.Get(HeaderIfMatch))
 	if err != nil {
+		if errors.Is(err, service.ErrSettingsPreconditionFailed) {
+			return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": err.Error()})
+		}
 		log.Printf("UpdateSettings Error: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
 	}
 
+	c.Response().Header().Set(HeaderETag, service.SettingsETag(settings))
+	return c.JSON(http.StatusOK, settings)
+}
+
+// PatchSettings ログインユーザーの設定の一部を更新する（JSON Merge Patch）
+// PATCH /api/v1/settings
+// If-Matchを指定した場合、他の端末が先に更新していれば412を返す
+func (h *SettingsHandler) PatchSettings(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	contentType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if contentType != MIMEMergePatchJSON && contentType != echo.MIMEApplicationJSON {
+		return c.JSON(http.StatusUnsupportedMediaType, map[string]string{"error": "content type must be " + MIMEMergePatchJSON})
+	}
+	patch, err := io.ReadAll(io.LimitReader(c.Request().Body, maxSettingsPatchSize+1))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	if len(patch) > maxSettingsPatchSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": "request body too large"})
+	}
+
+	settings, err := h.service.PatchSettings(c.Request().Context(), userID, patch, c.Request().Header.Get(HeaderIfMatch))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidSettings), errors.Is(err, service.ErrInvalidPreferences):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, service.ErrSettingsPreconditionFailed):
+			return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": err.Error()})
+		}
+		log.Printf("PatchSettings Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	c.Response().Header().Set(HeaderETag, service.SettingsETag(settings))
+	return c.JSON(http.StatusOK, settings)
+}
+
+// GetSettingsProfiles ログインユーザーの端末ごとの設定一覧を取得する
+// GET /api/v1/settings/profiles
+func (h *SettingsHandler) GetSettingsProfiles(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	profiles, err := h.service.ListProfiles(c.Request().Context(), userID)
+	if err != nil {
+		log.Printf("GetSettingsProfiles Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, profiles)
+}
+
+// SaveSettingsProfile 端末ごとの設定を作成・上書きする
+// PUT /api/v1/settings/profiles/:deviceId
+func (h *SettingsHandler) SaveSettingsProfile(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	deviceID := c.Param("deviceId")
+	if !deviceIDPattern.MatchString(deviceID) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid device id"})
+	}
+
+	req := new(SaveSettingsProfileRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || utf8.RuneCountInString(req.Name) > maxSettingsProfileNameLength {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "name must be 1-32 characters"})
+	}
+	if req.Overrides == nil {
+		req.Overrides = map[string]any{}
+	}
+
+	profile, err := h.service.SaveProfile(c.Request().Context(), &entity.SettingsProfile{
+		UserID:    userID,
+		DeviceID:  deviceID,
+		Name:      req.Name,
+		Overrides: req.Overrides,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidSettings), errors.Is(err, service.ErrInvalidPreferences):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, service.ErrSettingsProfileLimitReached):
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		case errors.Is(err, service.ErrUserNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found"})
+		}
+		log.Printf("SaveSettingsProfile Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, profile)
+}
+
+// DeleteSettingsProfile 端末ごとの設定を削除する
+// DELETE /api/v1/settings/profiles/:deviceId
+func (h *SettingsHandler) DeleteSettingsProfile(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	deviceID := c.Param("deviceId")
+	if !deviceIDPattern.MatchString(deviceID) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid device id"})
+	}
+
+	if err := h.service.DeleteProfile(c.Request().Context(), userID, deviceID); err != nil {
+		if errors.Is(err, service.ErrSettingsProfileNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "settings profile not found"})
+		}
+		log.Printf("DeleteSettingsProfile Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetEffectiveSettings 端末ごとの設定をアカウントの設定に重ねた、端末で実際に使う設定を取得する
+// GET /api/v1/settings/effective?deviceId=
+func (h *SettingsHandler) GetEffectiveSettings(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	deviceID := c.QueryParam("deviceId")
+	if deviceID != "" && !deviceIDPattern.MatchString(deviceID) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid device id"})
+	}
+
+	settings, err := h.service.ResolveSettings(c.Request().Context(), userID, deviceID)
+	if err != nil {
+		if errors.Is(err, service.ErrSettingsProfileConflict) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		log.Printf("GetEffectiveSettings Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
 	return c.JSON(http.StatusOK, settings)
 }
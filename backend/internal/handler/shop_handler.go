@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -50,3 +51,75 @@ func (h *ShopHandler) GetShopItemByID(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, item)
 }
+
+// PurchaseShopItem 指定したIDの商品を購入する
+// POST /api/v1/shop/:id/purchase
+func (h *ShopHandler) PurchaseShopItem(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid item id"})
+	}
+
+	result, err := h.service.Purchase(c.Request().Context(), userID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrShopItemNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "item not found", "code": "ITEM_NOT_FOUND"})
+		case errors.Is(err, service.ErrShopItemInactive):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "item is not available", "code": "ITEM_INACTIVE"})
+		case errors.Is(err, service.ErrShopItemIsUpgrade):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "upgrades must be purchased via the upgrade endpoint", "code": "ITEM_IS_UPGRADE"})
+		case errors.Is(err, service.ErrInsufficientCoins):
+			return c.JSON(http.StatusPaymentRequired, map[string]string{"error": "insufficient coins", "code": "INSUFFICIENT_COINS"})
+		case errors.Is(err, service.ErrUserNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found", "code": "USER_NOT_FOUND"})
+		}
+		log.Printf("PurchaseShopItem Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// UpgradeShopItem 恒久強化を次のレベルへ強化する
+// POST /api/v1/shop/:id/upgrade
+func (h *ShopHandler) UpgradeShopItem(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid item id"})
+	}
+
+	result, err := h.service.PurchaseUpgrade(c.Request().Context(), userID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrShopItemNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "item not found", "code": "ITEM_NOT_FOUND"})
+		case errors.Is(err, service.ErrShopItemInactive):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "item is not available", "code": "ITEM_INACTIVE"})
+		case errors.Is(err, service.ErrShopItemNotUpgrade):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "item is not an upgrade", "code": "ITEM_NOT_UPGRADE"})
+		case errors.Is(err, service.ErrUpgradeMaxLevel):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "upgrade already at max level", "code": "MAX_LEVEL"})
+		case errors.Is(err, service.ErrUpgradeConflict):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "upgrade level changed, please retry", "code": "UPGRADE_CONFLICT"})
+		case errors.Is(err, service.ErrInsufficientCoins):
+			return c.JSON(http.StatusPaymentRequired, map[string]string{"error": "insufficient coins", "code": "INSUFFICIENT_COINS"})
+		case errors.Is(err, service.ErrUserNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found", "code": "USER_NOT_FOUND"})
+		}
+		log.Printf("UpgradeShopItem Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type UnlockHandler struct {
+	service *service.UnlockService
+}
+
+func NewUnlockHandler(service *service.UnlockService) *UnlockHandler {
+	return &UnlockHandler{service: service}
+}
+
+// GetUnlockables 武器・必殺技の解放対象の一覧とログインユーザーの解放状況を取得する
+// GET /api/v1/unlockables
+func (h *UnlockHandler) GetUnlockables(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	unlockables, err := h.service.GetUnlockables(c.Request().Context(), userID)
+	if err != nil {
+		log.Printf("GetUnlockables Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, unlockables)
+}
+
+// GetMyUnlocks ログインユーザーがラン開始時に選択できる武器・必殺技を取得する
+// GET /api/v1/users/me/unlocks
+func (h *UnlockHandler) GetMyUnlocks(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	pickable, err := h.service.GetPickableSkills(c.Request().Context(), userID)
+	if err != nil {
+		log.Printf("GetMyUnlocks Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, pickable)
+}
+
+// PurchaseUnlock コインで武器・必殺技を解放する
+// POST /api/v1/unlockables/:code/unlock
+func (h *UnlockHandler) PurchaseUnlock(c echo.Context) error {
+	userID, ok := c.Get("userID").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}
+
+	result, err := h.service.PurchaseUnlock(c.Request().Context(), userID, c.Param("code"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnlockableNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "unlockable not found", "code": "UNLOCKABLE_NOT_FOUND"})
+		case errors.Is(err, service.ErrUnlockNotPurchasable):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "unlockable cannot be purchased with coins", "code": "NOT_PURCHASABLE"})
+		case errors.Is(err, service.ErrAlreadyUnlocked):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "already unlocked", "code": "ALREADY_UNLOCKED"})
+		case errors.Is(err, service.ErrInsufficientCoins):
+			return c.JSON(http.StatusPaymentRequired, map[string]string{"error": "insufficient coins", "code": "INSUFFICIENT_COINS"})
+		case errors.Is(err, service.ErrUserNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found", "code": "USER_NOT_FOUND"})
+		}
+		log.Printf("PurchaseUnlock Error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
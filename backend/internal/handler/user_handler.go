@@ -1,16 +1,15 @@
 package handler
 
 import (
+	"errors"
 	"log"
 	"net/http"
 
+	userMiddleware "github.com/RiTa-23/TRI-Survivor/backend/internal/middleware"
 	"github.com/RiTa-23/TRI-Survivor/backend/internal/service"
 	"github.com/labstack/echo/v4"
 )
 
-const MaxAddCoin = 1000000
-
-
 type UserHandler struct {
 	service *service.UserService
 }
@@ -33,6 +32,11 @@ func (h *UserHandler) SyncUser(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
 	}
 
+	// ゲストはPOST /api/v1/users/me/mergeでログインしたアカウントに引き継ぐ
+	if claims, ok := userMiddleware.ClaimsFromContext(c); ok && claims.IsGuest {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "guest accounts cannot be synced"})
+	}
+
 	req := new(CreateUserRequest)
 	if err := c.Bind(req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
@@ -73,36 +77,27 @@ func (h *UserHandler) GetMe(c echo.Context) error {
 }
 
 
-type AddCoinRequest struct {
-	Amount int `json:"amount"`
-}
-
-// AddCoin ユーザーにコインを追加する
-// POST /api/v1/users/me/coins
-func (h *UserHandler) AddCoin(c echo.Context) error {
+// GetCoinHistory ログインユーザーのコイン履歴を新しい順に取得する
+// GET /api/v1/users/me/coins/history?cursor=&limit=
+func (h *UserHandler) GetCoinHistory(c echo.Context) error {
 	userID, ok := c.Get("userID").(string)
 	if !ok {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
 	}
 
-	req := new(AddCoinRequest)
-	if err := c.Bind(req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
-	}
-
-	if req.Amount <= 0 || req.Amount > MaxAddCoin {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid amount"})
+	limit, err := parseLimit(c.QueryParam("limit"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
 	}
 
-	user, err := h.service.AddCoin(c.Request().Context(), userID, req.Amount)
+	history, err := h.service.GetCoinHistory(c.Request().Context(), userID, c.QueryParam("cursor"), limit)
 	if err != nil {
-		log.Printf("AddCoin Error: %v", err)
+		if errors.Is(err, service.ErrInvalidCursor) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid cursor"})
+		}
+		log.Printf("GetCoinHistory Error: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
 	}
-	if user == nil {
-		log.Printf("AddCoin Error: user not found after update (userID=%s)", userID)
-		return c.JSON(http.StatusNotFound, map[string]string{"error": "user not found"})
-	}
 
-	return c.JSON(http.StatusOK, user)
+	return c.JSON(http.StatusOK, history)
 }
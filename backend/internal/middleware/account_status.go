@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// RejectPendingDeletion 削除を申請したアカウントからのリクエストを拒否します
+// 猶予期間中も使える削除の取り消し・データのエクスポート・ユーザー情報の取得には適用しないでください。
+// AuthMiddlewareの後に適用してください。
+func RejectPendingDeletion(repo *repository.UserRepository) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID, ok := c.Get("userID").(string)
+			if !ok {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			}
+
+			pending, err := repo.IsPendingDeletion(c.Request().Context(), userID)
+			if err != nil {
+				log.Printf("RejectPendingDeletion Error: %v", err)
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			}
+			if pending {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error": "account is pending deletion",
+					"code":  "ACCOUNT_PENDING_DELETION",
+				})
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// contextKeyClaims AuthMiddlewareで検証したトークンのクレーム
+const contextKeyClaims = "claims"
+
+// defaultAudience Supabaseがログインユーザーに発行するトークンのaud
+const defaultAudience = "authenticated"
+
+// defaultLeeway 発行元とのクロックのずれとして許容する時間
+const defaultLeeway = 30 * time.Second
+
+// Claims アクセストークンから取り出したユーザー情報
+type Claims struct {
+	jwt.RegisteredClaims
+	Email       string         `json:"email,omitempty"`
+	Role        string         `json:"role,omitempty"`
+	SessionID   string         `json:"session_id,omitempty"`
+	AppMetadata map[string]any `json:"app_metadata,omitempty"`
+	IsGuest     bool           `json:"is_guest,omitempty"` // バックエンドが発行したゲスト用トークン
+}
+
+// AppRoles app_metadata.roles（配列）またはapp_metadata.role（文字列）から権限を読み取ります
+// app_metadataはユーザー自身では変更できないため、権限の付与に使用できます
+func (c *Claims) AppRoles() []string {
+	var roles []string
+	if list, ok := c.AppMetadata["roles"].([]any); ok {
+		for _, v := range list {
+			if role, ok := v.(string); ok && role != "" {
+				roles = append(roles, role)
+			}
+		}
+	}
+	if role, ok := c.AppMetadata["role"].(string); ok && role != "" {
+		roles = append(roles, role)
+	}
+	return roles
+}
+
+// ClaimsFromContext AuthMiddlewareでセットしたクレームを返します
+func ClaimsFromContext(c echo.Context) (*Claims, bool) {
+	claims, ok := c.Get(contextKeyClaims).(*Claims)
+	return claims, ok
+}
+
+// VerifierConfig トークンのクレームの検証条件
+type VerifierConfig struct {
+	Issuer     string        // 空の場合はissを検証しない
+	Audience   string        // 空の場合はaudを検証しない
+	Algorithms []string      // 許可する署名アルゴリズム
+	Leeway     time.Duration // exp・nbf・iatの検証で許容するずれ
+}
+
+// parserOptions jwt.Parserに渡す検証オプションを返します
+func (cfg VerifierConfig) parserOptions() []jwt.ParserOption {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(cfg.Algorithms),
+		jwt.WithLeeway(cfg.Leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	return opts
+}
+
+// loadVerifierConfig 環境変数からクレームの検証条件を読み込みます
+// AUTH_ISSUER・AUTH_AUDIENCEは空文字を設定すると検証しません
+func loadVerifierConfig(defaultIssuer string, defaultAlgorithms, allowedAlgorithms []string) (VerifierConfig, error) {
+	cfg := VerifierConfig{
+		Issuer:     defaultIssuer,
+		Audience:   defaultAudience,
+		Algorithms: defaultAlgorithms,
+		Leeway:     defaultLeeway,
+	}
+	if v, ok := os.LookupEnv("AUTH_ISSUER"); ok {
+		cfg.Issuer = v
+	}
+	if v, ok := os.LookupEnv("AUTH_AUDIENCE"); ok {
+		cfg.Audience = v
+	}
+	if v := os.Getenv("AUTH_ALLOWED_ALGS"); v != "" {
+		cfg.Algorithms = nil
+		for _, alg := range strings.Split(v, ",") {
+			alg = strings.TrimSpace(alg)
+			// 鍵の種類と合わないアルゴリズム（JWKSでのHS256など）は受け付けない
+			if !slices.Contains(allowedAlgorithms, alg) {
+				return cfg, fmt.Errorf("AUTH_ALLOWED_ALGS: %q is not allowed in this AUTH_MODE (allowed: %s)", alg, strings.Join(allowedAlgorithms, ","))
+			}
+			cfg.Algorithms = append(cfg.Algorithms, alg)
+		}
+	}
+	if v := os.Getenv("AUTH_LEEWAY"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return cfg, fmt.Errorf("AUTH_LEEWAY: invalid duration %q", v)
+		}
+		cfg.Leeway = d
+	}
+	return cfg, nil
+}
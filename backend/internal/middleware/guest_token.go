@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// defaultGuestTokenIssuer ゲスト用トークンのiss（Supabaseのトークンと区別するために使用）
+	defaultGuestTokenIssuer = "tri-survivor-guest"
+	// defaultGuestTokenTTL ゲスト用トークンの有効期間
+	defaultGuestTokenTTL = 30 * 24 * time.Hour
+	// minGuestTokenSecretLength GUEST_TOKEN_SECRETに必要な長さ（バイト）
+	minGuestTokenSecretLength = 32
+)
+
+// GuestTokens ゲストアカウント用のトークンを発行・検証します (HS256)
+type GuestTokens struct {
+	secret []byte
+	cfg    VerifierConfig
+	ttl    time.Duration
+}
+
+// NewGuestTokensFromEnv 環境変数からゲスト用トークンの設定を読み込みます
+// GUEST_TOKEN_SECRETが未設定の場合はゲストアカウントを無効とし、nilを返します
+func NewGuestTokensFromEnv() (*GuestTokens, error) {
+	secret := os.Getenv("GUEST_TOKEN_SECRET")
+	if secret == "" {
+		return nil, nil
+	}
+	if len(secret) < minGuestTokenSecretLength {
+		return nil, fmt.Errorf("GUEST_TOKEN_SECRET must be at least %d bytes", minGuestTokenSecretLength)
+	}
+
+	ttl := defaultGuestTokenTTL
+	if v := os.Getenv("GUEST_TOKEN_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("GUEST_TOKEN_TTL: invalid duration %q", v)
+		}
+		ttl = d
+	}
+
+	return &GuestTokens{
+		secret: []byte(secret),
+		cfg: VerifierConfig{
+			Issuer:     defaultGuestTokenIssuer,
+			Audience:   defaultAudience,
+			Algorithms: []string{"HS256"},
+			Leeway:     defaultLeeway,
+		},
+		ttl: ttl,
+	}, nil
+}
+
+// Issue ゲストユーザーのトークンを発行し、有効期限とともに返します
+func (g *GuestTokens) Issue(userID string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(g.ttl)
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    g.cfg.Issuer,
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{g.cfg.Audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		Role:    "authenticated",
+		IsGuest: true,
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(g.secret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// Verify ゲスト用トークンを検証します
+func (g *GuestTokens) Verify(ctx context.Context, tokenString string) (*Claims, error) {
+	claims, err := parseToken(tokenString, func(*jwt.Token) (any, error) { return g.secret, nil }, g.cfg)
+	if err != nil {
+		return nil, err
+	}
+	if !claims.IsGuest {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	return claims, nil
+}
+
+func (g *GuestTokens) Status() VerifierStatus {
+	return VerifierStatus{Ready: true}
+}
+
+// guestAwareVerifier issに応じて、ゲスト用トークンとそれ以外のトークンの検証を振り分けます
+type guestAwareVerifier struct {
+	primary TokenVerifier
+	guest   *GuestTokens
+}
+
+// WithGuestTokens ゲスト用トークンも受け付けるTokenVerifierを返します（guestがnilの場合はprimaryをそのまま返します）
+func WithGuestTokens(primary TokenVerifier, guest *GuestTokens) TokenVerifier {
+	if guest == nil {
+		return primary
+	}
+	return &guestAwareVerifier{primary: primary, guest: guest}
+}
+
+func (v *guestAwareVerifier) Verify(ctx context.Context, tokenString string) (*Claims, error) {
+	// 振り分けのためだけに署名を検証せずにissを読み取る（検証はそれぞれのVerifierで行う）
+	unverified := new(Claims)
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, unverified); err != nil {
+		return nil, err
+	}
+	if unverified.Issuer == v.guest.cfg.Issuer {
+		return v.guest.Verify(ctx, tokenString)
+	}
+
+	claims, err := v.primary.Verify(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+	// ゲストかどうかはバックエンドが発行したトークンでのみ判断する
+	claims.IsGuest = false
+	return claims, nil
+}
+
+func (v *guestAwareVerifier) Status() VerifierStatus {
+	return v.primary.Status()
+}
@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed 保存済みレスポンスを再送した場合に付与するヘッダー
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	// idempotencyKeyTTL この期間を過ぎたキーは再利用可能とみなします
+	idempotencyKeyTTL = 24 * time.Hour
+)
+
+// IdempotencyMiddleware Idempotency-Keyヘッダーを使ってリクエストの重複実行を防ぎます
+// 同じキーで同じリクエストが再送された場合は保存済みのレスポンスを返し、
+// 異なる内容のリクエストで同じキーが使われた場合は409を返します。
+// ヘッダーが無いリクエストはそのまま処理します。AuthMiddlewareの後に適用してください。
+func IdempotencyMiddleware(repo *repository.IdempotencyRepository) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(HeaderIdempotencyKey)
+			if key == "" {
+				return next(c)
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "idempotency key is too long"})
+			}
+
+			userID, ok := c.Get("userID").(string)
+			if !ok {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			}
+
+			// ハッシュ計算のためにボディを読み出し、ハンドラー用に戻しておく
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+			requestHash := hashRequest(c.Request(), body)
+
+			ctx := c.Request().Context()
+			record := &entity.IdempotencyKey{
+				UserID:      userID,
+				Key:         key,
+				RequestHash: requestHash,
+			}
+			reserved, err := repo.Reserve(ctx, record)
+			if err != nil {
+				log.Printf("Idempotency Reserve Error: %v", err)
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			}
+
+			if !reserved {
+				existing, err := repo.Find(ctx, userID, key)
+				if err != nil {
+					log.Printf("Idempotency Find Error: %v", err)
+					return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+				}
+
+				// 期限切れのキーは破棄して新しいリクエストとして扱う
+				if existing != nil && time.Since(existing.CreatedAt) > idempotencyKeyTTL {
+					if err := repo.Delete(ctx, userID, key); err != nil {
+						log.Printf("Idempotency Delete Error: %v", err)
+						return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+					}
+					if reserved, err = repo.Reserve(ctx, record); err != nil {
+						log.Printf("Idempotency Reserve Error: %v", err)
+						return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+					}
+					existing = nil
+				}
+
+				if !reserved {
+					if existing == nil {
+						// 並行リクエストによって削除・再登録された直後
+						return c.JSON(http.StatusConflict, map[string]string{"error": "request with this idempotency key is in progress"})
+					}
+					if existing.RequestHash != requestHash {
+						return c.JSON(http.StatusConflict, map[string]string{"error": "idempotency key reused with a different request"})
+					}
+					if existing.StatusCode == nil {
+						return c.JSON(http.StatusConflict, map[string]string{"error": "request with this idempotency key is in progress"})
+					}
+					c.Response().Header().Set(HeaderIdempotentReplayed, "true")
+					return c.Blob(*existing.StatusCode, existing.ContentType, existing.ResponseBody)
+				}
+			}
+
+			// ハンドラーのレスポンスを記録しながらクライアントへ返す
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+
+			handlerErr := next(c)
+
+			status := c.Response().Status
+			if handlerErr != nil || !c.Response().Committed || status >= http.StatusInternalServerError {
+				// サーバーエラーの場合は同じキーでの再試行を許可する
+				if err := repo.Delete(ctx, userID, key); err != nil {
+					log.Printf("Idempotency Delete Error: %v", err)
+				}
+				return handlerErr
+			}
+
+			contentType := c.Response().Header().Get(echo.HeaderContentType)
+			if err := repo.SaveResponse(ctx, userID, key, status, contentType, recorder.body.Bytes()); err != nil {
+				log.Printf("Idempotency SaveResponse Error: %v", err)
+			}
+			return nil
+		}
+	}
+}
+
+// hashRequest メソッド・パス・クエリ・If-Match・ボディからリクエストのハッシュを計算します
+// If-Matchが異なる再試行は別のリクエストとして扱い、前回のレスポンスを返さないようにします
+func hashRequest(req *http.Request, body []byte) string {
+	h := sha256.New()
+	for _, part := range []string{req.Method, req.URL.Path, req.URL.RawQuery, req.Header.Get("If-Match")} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder レスポンスボディを書き込みながら記録するResponseWriter
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// contextKeyRoles LoadRolesで読み込んだ権限（DBとJWTの和集合）
+const contextKeyRoles = "roles"
+
+// LoadRoles DBに登録された権限とJWTの権限を読み込み、コンテキストにセットします
+// AuthMiddlewareの後に適用してください。
+func LoadRoles(repo *repository.UserRoleRepository) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID, ok := c.Get("userID").(string)
+			if !ok {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			}
+
+			roles, err := repo.FindRolesByUserID(c.Request().Context(), userID)
+			if err != nil {
+				log.Printf("LoadRoles Error: %v", err)
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			}
+			if claims, ok := ClaimsFromContext(c); ok {
+				for _, role := range claims.AppRoles() {
+					if !slices.Contains(roles, role) {
+						roles = append(roles, role)
+					}
+				}
+			}
+
+			c.Set(contextKeyRoles, roles)
+			return next(c)
+		}
+	}
+}
+
+// Roles LoadRolesで読み込んだ権限を返します
+func Roles(c echo.Context) []string {
+	roles, _ := c.Get(contextKeyRoles).([]string)
+	return roles
+}
+
+// HasRole 指定した権限のいずれかを持っているかどうかを返します
+func HasRole(c echo.Context, roles ...string) bool {
+	for _, role := range Roles(c) {
+		if slices.Contains(roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireRole 指定した権限のいずれかを持つユーザーのみ許可します
+// LoadRolesの後に適用してください。
+func RequireRole(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !HasRole(c, roles...) {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": "forbidden"})
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// MintHS256Token HS256Verifierで検証できるトークンを発行します（ローカル開発・結合テスト用）
+// extraに指定したクレーム（iss・emailやapp_metadataなど）は標準のクレームを上書きします
+func MintHS256Token(secret []byte, userID string, ttl time.Duration, extra jwt.MapClaims) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub":  userID,
+		"aud":  defaultAudience,
+		"role": "authenticated",
+		"iat":  now.Unix(),
+		"exp":  now.Add(ttl).Unix(),
+	}
+	for k, v := range extra {
+		claims[k] = v
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+}
@@ -0,0 +1,302 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/MicahParks/jwkset"
+	"github.com/MicahParks/keyfunc/v3"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 認証方式（AUTH_MODE）
+const (
+	AuthModeSupabase = "supabase"  // SupabaseのJWKSをHTTPで取得する（デフォルト）
+	AuthModeJWKSFile = "jwks_file" // ローカルのJWKSファイルを使用する
+	AuthModeHS256    = "hs256"     // 共有シークレットによるHS256署名（ローカル開発・テスト用）
+)
+
+// asymmetricAlgorithms JWKSで検証する場合に許可する署名アルゴリズム（SupabaseはRS256またはES256を使用）
+var asymmetricAlgorithms = []string{"RS256", "ES256"}
+
+const (
+	// jwksRetryMin JWKSの取得に失敗したときの最初の再試行間隔
+	jwksRetryMin = time.Second
+	// jwksRetryMax JWKSの取得を再試行する間隔の上限
+	jwksRetryMax = time.Minute
+)
+
+// ErrVerifierUnavailable 検証に使う鍵が利用できない（トークン自体の不正ではない）
+var ErrVerifierUnavailable = errors.New("token verifier unavailable")
+
+// UnavailableError 鍵が利用できるようになるまでの目安とともに返すエラー
+type UnavailableError struct {
+	RetryAfter time.Duration
+}
+
+func (e *UnavailableError) Error() string {
+	return fmt.Sprintf("%v (retry after %s)", ErrVerifierUnavailable, e.RetryAfter)
+}
+
+func (e *UnavailableError) Unwrap() error {
+	return ErrVerifierUnavailable
+}
+
+// RetryAfterSeconds Retry-Afterヘッダーの値（秒、最低1秒）を返します
+func RetryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(max(int(math.Ceil(d.Seconds())), 1))
+}
+
+// VerifierStatus 検証に使う鍵の準備状況
+type VerifierStatus struct {
+	Ready      bool          `json:"ready"`
+	Error      string        `json:"error,omitempty"`
+	RetryAfter time.Duration `json:"-"`
+}
+
+// TokenVerifier アクセストークンの署名を検証し、クレームを返します
+type TokenVerifier interface {
+	Verify(ctx context.Context, tokenString string) (*Claims, error)
+	Status() VerifierStatus
+}
+
+// NewTokenVerifierFromEnv AUTH_MODEに応じたTokenVerifierを作成します
+// リモートのJWKSを使用する場合は、ctxが終了するまでバックグラウンドで取得・更新を行います
+func NewTokenVerifierFromEnv(ctx context.Context) (TokenVerifier, error) {
+	switch mode := os.Getenv("AUTH_MODE"); mode {
+	case "", AuthModeSupabase:
+		refID := os.Getenv("SUPABASE_REFERENCE_ID")
+		url := os.Getenv("AUTH_JWKS_URL")
+		if url == "" && refID == "" {
+			return nil, fmt.Errorf("SUPABASE_REFERENCE_ID is not set")
+		}
+		issuer := ""
+		if refID != "" {
+			// SupabaseのJWKS URLと発行元を構築
+			issuer = fmt.Sprintf("https://%s.supabase.co/auth/v1", refID)
+			if url == "" {
+				url = issuer + "/.well-known/jwks.json"
+			}
+		}
+		cfg, err := loadVerifierConfig(issuer, asymmetricAlgorithms, asymmetricAlgorithms)
+		if err != nil {
+			return nil, err
+		}
+		verifier := NewRemoteJWKSVerifier(url, cfg)
+		verifier.Start(ctx)
+		return verifier, nil
+	case AuthModeJWKSFile:
+		path := os.Getenv("AUTH_JWKS_FILE")
+		if path == "" {
+			return nil, fmt.Errorf("AUTH_JWKS_FILE is not set")
+		}
+		cfg, err := loadVerifierConfig("", asymmetricAlgorithms, asymmetricAlgorithms)
+		if err != nil {
+			return nil, err
+		}
+		return NewLocalJWKSVerifier(path, cfg)
+	case AuthModeHS256:
+		secret := os.Getenv("AUTH_JWT_SECRET")
+		if secret == "" {
+			return nil, fmt.Errorf("AUTH_JWT_SECRET is not set")
+		}
+		cfg, err := loadVerifierConfig("", []string{"HS256"}, []string{"HS256"})
+		if err != nil {
+			return nil, err
+		}
+		return NewHS256Verifier([]byte(secret), cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown AUTH_MODE: %s", mode)
+	}
+}
+
+// parseToken 署名・アルゴリズム・有効期限・発行元・対象を検証してクレームを取り出します
+func parseToken(tokenString string, keyFunc jwt.Keyfunc, cfg VerifierConfig) (*Claims, error) {
+	claims := new(Claims)
+	token, err := jwt.ParseWithClaims(tokenString, claims, keyFunc, cfg.parserOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, jwt.ErrTokenSignatureInvalid
+	}
+	if claims.Subject == "" {
+		return nil, jwt.ErrTokenRequiredClaimMissing
+	}
+	return claims, nil
+}
+
+// RemoteJWKSVerifier URLから取得したJWKSで検証します (RS256/ES256)
+// 取得はStartでバックグラウンドに開始し、失敗した場合はバックオフしながら再試行します。
+// 取得後の更新はkeyfuncライブラリがキャッシュと合わせて自動的に処理します
+type RemoteJWKSVerifier struct {
+	url string
+	cfg VerifierConfig
+
+	mu          sync.RWMutex
+	jwks        keyfunc.Keyfunc
+	stopRefresh context.CancelFunc
+	lastErr     error
+	nextRetryAt time.Time
+}
+
+func NewRemoteJWKSVerifier(url string, cfg VerifierConfig) *RemoteJWKSVerifier {
+	return &RemoteJWKSVerifier{url: url, cfg: cfg, nextRetryAt: time.Now()}
+}
+
+// Start JWKSの取得をバックグラウンドで開始します（ctxが終了すると再試行と更新を停止します）
+func (v *RemoteJWKSVerifier) Start(ctx context.Context) {
+	go func() {
+		backoff := jwksRetryMin
+		for {
+			err := v.fetch(ctx)
+			if err == nil {
+				log.Printf("JWKS initialized with URL: %s", v.url)
+				return
+			}
+			log.Printf("Failed to initialize JWKS from %s (retrying in %s): %v", v.url, backoff, err)
+			v.mu.Lock()
+			v.lastErr = err
+			v.nextRetryAt = time.Now().Add(backoff)
+			v.mu.Unlock()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, jwksRetryMax)
+		}
+	}()
+}
+
+// fetch JWKSを取得し、鍵が1つ以上得られた場合のみ検証に使用します
+func (v *RemoteJWKSVerifier) fetch(ctx context.Context) error {
+	fetchCtx, cancel := context.WithCancel(ctx)
+	var fetchErr error
+	jwks, err := keyfunc.NewDefaultOverrideCtx(fetchCtx, []string{v.url}, keyfunc.Override{
+		RefreshErrorHandlerFunc: func(u string) func(ctx context.Context, err error) {
+			return func(ctx context.Context, err error) {
+				// 初回の取得エラーは呼び出し元で扱い、以降の定期更新のエラーはログに残す
+				if fetchErr == nil && !v.Ready() {
+					fetchErr = err
+					return
+				}
+				log.Printf("Failed to refresh JWKS from %s: %v", u, err)
+			}
+		},
+	})
+	if err == nil && fetchErr != nil {
+		err = fetchErr
+	}
+	if err == nil {
+		var keys []jwkset.JWK
+		keys, err = jwks.Storage().KeyReadAll(ctx)
+		if err == nil && len(keys) == 0 {
+			err = errors.New("JWKS contains no keys")
+		}
+	}
+	if err != nil {
+		// 失敗した取得の定期更新は停止する
+		cancel()
+		return err
+	}
+
+	v.mu.Lock()
+	v.jwks = jwks
+	v.stopRefresh = cancel
+	v.lastErr = nil
+	v.mu.Unlock()
+	return nil
+}
+
+// Stop 取得済みのJWKSの定期更新を停止します
+func (v *RemoteJWKSVerifier) Stop() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.stopRefresh != nil {
+		v.stopRefresh()
+	}
+}
+
+// Ready JWKSを取得済みかどうかを返します
+func (v *RemoteJWKSVerifier) Ready() bool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.jwks != nil
+}
+
+func (v *RemoteJWKSVerifier) Status() VerifierStatus {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if v.jwks != nil {
+		return VerifierStatus{Ready: true}
+	}
+	status := VerifierStatus{RetryAfter: max(time.Until(v.nextRetryAt), time.Second)}
+	if v.lastErr != nil {
+		status.Error = v.lastErr.Error()
+	}
+	return status
+}
+
+func (v *RemoteJWKSVerifier) Verify(ctx context.Context, tokenString string) (*Claims, error) {
+	v.mu.RLock()
+	jwks := v.jwks
+	v.mu.RUnlock()
+	if jwks == nil {
+		return nil, &UnavailableError{RetryAfter: v.Status().RetryAfter}
+	}
+	return parseToken(tokenString, jwks.KeyfuncCtx(ctx), v.cfg)
+}
+
+// LocalJWKSVerifier ローカルのJWKSファイルで検証します (RS256/ES256)
+// ネットワークに接続できない環境での開発・テストに使用します
+type LocalJWKSVerifier struct {
+	jwks keyfunc.Keyfunc
+	cfg  VerifierConfig
+}
+
+func NewLocalJWKSVerifier(path string, cfg VerifierConfig) (*LocalJWKSVerifier, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file %s: %w", path, err)
+	}
+	jwks, err := keyfunc.NewJWKSetJSON(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS file %s: %w", path, err)
+	}
+	return &LocalJWKSVerifier{jwks: jwks, cfg: cfg}, nil
+}
+
+func (v *LocalJWKSVerifier) Status() VerifierStatus {
+	return VerifierStatus{Ready: true}
+}
+
+func (v *LocalJWKSVerifier) Verify(ctx context.Context, tokenString string) (*Claims, error) {
+	return parseToken(tokenString, v.jwks.KeyfuncCtx(ctx), v.cfg)
+}
+
+// HS256Verifier 共有シークレットで検証します
+type HS256Verifier struct {
+	secret []byte
+	cfg    VerifierConfig
+}
+
+func NewHS256Verifier(secret []byte, cfg VerifierConfig) *HS256Verifier {
+	return &HS256Verifier{secret: secret, cfg: cfg}
+}
+
+func (v *HS256Verifier) Status() VerifierStatus {
+	return VerifierStatus{Ready: true}
+}
+
+func (v *HS256Verifier) Verify(ctx context.Context, tokenString string) (*Claims, error) {
+	return parseToken(tokenString, func(*jwt.Token) (any, error) { return v.secret, nil }, v.cfg)
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/uptrace/bun"
+)
+
+type AchievementRepository struct {
+	db bun.IDB
+}
+
+func NewAchievementRepository(db *bun.DB) *AchievementRepository {
+	return &AchievementRepository{db: db}
+}
+
+// WithTx トランザクション内で操作するリポジトリを返します
+func (r *AchievementRepository) WithTx(tx bun.Tx) *AchievementRepository {
+	return &AchievementRepository{db: tx}
+}
+
+// FindActive 有効な実績の一覧を表示順に取得します
+func (r *AchievementRepository) FindActive(ctx context.Context) ([]entity.Achievement, error) {
+	achievements := []entity.Achievement{}
+	err := r.db.NewSelect().
+		Model(&achievements).
+		Where("is_active = ?", true).
+		Order("sort_order ASC", "id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return achievements, nil
+}
+
+// FindUnlockedByUserID ユーザーが解除済みの実績を取得します
+func (r *AchievementRepository) FindUnlockedByUserID(ctx context.Context, userID string) ([]entity.UserAchievement, error) {
+	unlocked := []entity.UserAchievement{}
+	err := r.db.NewSelect().
+		Model(&unlocked).
+		Where("user_id = ?", userID).
+		Order("unlocked_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return unlocked, nil
+}
+
+// Unlock 実績を解除済みとして登録します
+// 既に解除済みの場合はfalseを返します
+func (r *AchievementRepository) Unlock(ctx context.Context, ua *entity.UserAchievement) (bool, error) {
+	res, err := r.db.NewInsert().
+		Model(ua).
+		On("CONFLICT (user_id, achievement_id) DO NOTHING").
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// FindUnlockedCodes ユーザーが解除済みの実績のコードを取得します
+func (r *AchievementRepository) FindUnlockedCodes(ctx context.Context, userID string) ([]string, error) {
+	codes := []string{}
+	err := r.db.NewSelect().
+		Model((*entity.UserAchievement)(nil)).
+		Join("JOIN achievements AS achievement ON achievement.id = user_achievement.achievement_id").
+		Column("achievement.code").
+		Where("user_achievement.user_id = ?", userID).
+		Scan(ctx, &codes)
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/uptrace/bun"
+)
+
+type CoinTransactionRepository struct {
+	db bun.IDB
+}
+
+func NewCoinTransactionRepository(db *bun.DB) *CoinTransactionRepository {
+	return &CoinTransactionRepository{db: db}
+}
+
+// WithTx トランザクション内で操作するリポジトリを返します
+func (r *CoinTransactionRepository) WithTx(tx bun.Tx) *CoinTransactionRepository {
+	return &CoinTransactionRepository{db: tx}
+}
+
+// Create 台帳にレコードを追加します
+func (r *CoinTransactionRepository) Create(ctx context.Context, txn *entity.CoinTransaction) error {
+	_, err := r.db.NewInsert().
+		Model(txn).
+		Returning("*").
+		Exec(ctx)
+	return err
+}
+
+// CopyToUser fromUserIDの台帳を、元のIDとユーザーを記録してtoUserIDに複製し、件数を返します
+// 作成日時は元のレコードのものを維持します
+func (r *CoinTransactionRepository) CopyToUser(ctx context.Context, fromUserID, toUserID string) (int, error) {
+	res, err := r.db.NewRaw(`
+		INSERT INTO coin_transactions (user_id, delta, reason, reference_id, balance_after, created_at, merged_from_user_id, original_id)
+		SELECT ?, delta, reason, reference_id, balance_after, created_at, user_id, id
+		FROM coin_transactions
+		WHERE user_id = ?
+		ORDER BY id`, toUserID, fromUserID).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+// FindByUserID ユーザーの台帳を新しい順に取得します
+// beforeIDが0より大きい場合は、そのIDより古いレコードのみを対象とします
+func (r *CoinTransactionRepository) FindByUserID(ctx context.Context, userID string, beforeID int64, limit int) ([]entity.CoinTransaction, error) {
+	txns := []entity.CoinTransaction{}
+	q := r.db.NewSelect().
+		Model(&txns).
+		Where("user_id = ?", userID)
+	if beforeID > 0 {
+		q = q.Where("id < ?", beforeID)
+	}
+	err := q.
+		Order("id DESC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return txns, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/uptrace/bun"
+)
+
+type IdempotencyRepository struct {
+	db bun.IDB
+}
+
+func NewIdempotencyRepository(db *bun.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Reserve 冪等性キーを処理中として登録します
+// 既に同じキーが登録されている場合はfalseを返します
+func (r *IdempotencyRepository) Reserve(ctx context.Context, key *entity.IdempotencyKey) (bool, error) {
+	res, err := r.db.NewInsert().
+		Model(key).
+		On("CONFLICT (user_id, idempotency_key) DO NOTHING").
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// Find ユーザーIDとキーから登録済みの冪等性キーを取得します
+func (r *IdempotencyRepository) Find(ctx context.Context, userID, key string) (*entity.IdempotencyKey, error) {
+	record := new(entity.IdempotencyKey)
+	err := r.db.NewSelect().
+		Model(record).
+		Where("user_id = ?", userID).
+		Where("idempotency_key = ?", key).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Not Found
+		}
+		return nil, err
+	}
+	return record, nil
+}
+
+// SaveResponse 処理結果のレスポンスを保存します
+func (r *IdempotencyRepository) SaveResponse(ctx context.Context, userID, key string, statusCode int, contentType string, body []byte) error {
+	_, err := r.db.NewUpdate().
+		Model((*entity.IdempotencyKey)(nil)).
+		Set("status_code = ?", statusCode).
+		Set("content_type = ?", contentType).
+		Set("response_body = ?", body).
+		Where("user_id = ?", userID).
+		Where("idempotency_key = ?", key).
+		Exec(ctx)
+	return err
+}
+
+// Delete 冪等性キーを削除します（処理失敗時に再試行を許可するため）
+func (r *IdempotencyRepository) Delete(ctx context.Context, userID, key string) error {
+	_, err := r.db.NewDelete().
+		Model((*entity.IdempotencyKey)(nil)).
+		Where("user_id = ?", userID).
+		Where("idempotency_key = ?", key).
+		Exec(ctx)
+	return err
+}
@@ -2,19 +2,26 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 
 	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
 	"github.com/uptrace/bun"
 )
 
 type ItemRepository struct {
-	db *bun.DB
+	db bun.IDB
 }
 
 func NewItemRepository(db *bun.DB) *ItemRepository {
 	return &ItemRepository{db: db}
 }
 
+// WithTx トランザクション内で操作するリポジトリを返します
+func (r *ItemRepository) WithTx(tx bun.Tx) *ItemRepository {
+	return &ItemRepository{db: tx}
+}
+
 // FindByUserID ユーザーIDに紐づく所持アイテム一覧を取得します（ショップ情報も含む）
 func (r *ItemRepository) FindByUserID(ctx context.Context, userID string) ([]entity.Item, error) {
 	items := []entity.Item{}
@@ -30,6 +37,19 @@ func (r *ItemRepository) FindByUserID(ctx context.Context, userID string) ([]ent
 	return items, nil
 }
 
+// AddQuantity アイテムの所持数を加算します（未所持の場合は新規登録）
+// 加算後の所持アイテムをitemに反映します
+func (r *ItemRepository) AddQuantity(ctx context.Context, item *entity.Item) error {
+	_, err := r.db.NewInsert().
+		Model(item).
+		On("CONFLICT (user_id, item_id) DO UPDATE").
+		Set("quantity = item.quantity + EXCLUDED.quantity").
+		Set("updated_at = now()").
+		Returning("*").
+		Exec(ctx)
+	return err
+}
+
 // Upsert アイテムの所持数を更新または新規登録します
 func (r *ItemRepository) Upsert(ctx context.Context, item *entity.Item) error {
 	_, err := r.db.NewInsert().
@@ -41,3 +61,78 @@ func (r *ItemRepository) Upsert(ctx context.Context, item *entity.Item) error {
 		Exec(ctx)
 	return err
 }
+
+// FindUpgradesByUserID ユーザーが所持している恒久強化を取得します（ショップ情報も含む）
+func (r *ItemRepository) FindUpgradesByUserID(ctx context.Context, userID string) ([]entity.Item, error) {
+	items := []entity.Item{}
+	err := r.db.NewSelect().
+		Model(&items).
+		Relation("Shop").
+		Where("item.user_id = ?", userID).
+		Where("item.quantity > 0").
+		Where("shop.skill_type IS NOT NULL").
+		Order("item.item_id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// FindByUserAndItemID ユーザーの指定したアイテムの所持情報を取得します
+func (r *ItemRepository) FindByUserAndItemID(ctx context.Context, userID string, itemID int) (*entity.Item, error) {
+	item := new(entity.Item)
+	err := r.db.NewSelect().
+		Model(item).
+		Where("user_id = ?", userID).
+		Where("item_id = ?", itemID).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Not Found
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+// SetLevelIfCurrent 所持数（レベル）が fromLevel のままの場合に限り item.Quantity に更新します
+// 同時に更新されていた場合は false を返します
+func (r *ItemRepository) SetLevelIfCurrent(ctx context.Context, item *entity.Item, fromLevel int) (bool, error) {
+	res, err := r.db.NewInsert().
+		Model(item).
+		On("CONFLICT (user_id, item_id) DO UPDATE").
+		Set("quantity = EXCLUDED.quantity").
+		Set("updated_at = now()").
+		Where("item.quantity = ?", fromLevel).
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Consume アイテムの所持数を減算します
+// 所持数が足りない場合は更新せずにfalseを返します
+func (r *ItemRepository) Consume(ctx context.Context, userID string, itemID, quantity int) (bool, error) {
+	res, err := r.db.NewUpdate().
+		Model((*entity.Item)(nil)).
+		Set("quantity = item.quantity - ?", quantity).
+		Where("user_id = ?", userID).
+		Where("item_id = ?", itemID).
+		Where("quantity >= ?", quantity).
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/uptrace/bun"
+)
+
+type LeaderboardRepository struct {
+	db bun.IDB
+}
+
+func NewLeaderboardRepository(db *bun.DB) *LeaderboardRepository {
+	return &LeaderboardRepository{db: db}
+}
+
+// WithTx トランザクション内で操作するリポジトリを返します
+func (r *LeaderboardRepository) WithTx(tx bun.Tx) *LeaderboardRepository {
+	return &LeaderboardRepository{db: tx}
+}
+
+// UpsertBest 自己ベストを更新した場合のみエントリーを登録・更新します
+func (r *LeaderboardRepository) UpsertBest(ctx context.Context, entry *entity.LeaderboardEntry) error {
+	_, err := r.db.NewInsert().
+		Model(entry).
+		On("CONFLICT (board, period, period_start, user_id) DO UPDATE").
+		Set("run_id = EXCLUDED.run_id").
+		Set("score = EXCLUDED.score").
+		Set("rank_value = EXCLUDED.rank_value").
+		Set("achieved_at = EXCLUDED.achieved_at").
+		Where("leaderboard_entry.rank_value < EXCLUDED.rank_value").
+		Exec(ctx)
+	return err
+}
+
+// FindTop 上位からlimit件のエントリーを取得します（ユーザー情報も含む）
+func (r *LeaderboardRepository) FindTop(ctx context.Context, board, period string, periodStart time.Time, limit int) ([]entity.LeaderboardEntry, error) {
+	entries := []entity.LeaderboardEntry{}
+	err := r.boardQuery(&entries, board, period, periodStart).
+		OrderExpr("leaderboard_entry.rank_value DESC, leaderboard_entry.achieved_at ASC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// FindByUserID ユーザーのエントリーを取得します
+func (r *LeaderboardRepository) FindByUserID(ctx context.Context, board, period string, periodStart time.Time, userID string) (*entity.LeaderboardEntry, error) {
+	entry := new(entity.LeaderboardEntry)
+	err := r.db.NewSelect().
+		Model(entry).
+		Relation("User").
+		Where("leaderboard_entry.board = ?", board).
+		Where("leaderboard_entry.period = ?", period).
+		Where("leaderboard_entry.period_start = ?", periodStart.Format(time.DateOnly)).
+		Where("leaderboard_entry.user_id = ?", userID).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Not Found
+		}
+		return nil, err
+	}
+	return entry, nil
+}
+
+// MergeUser fromUserIDのエントリーをtoUserIDの自己ベストとして取り込みます（より良い記録のみ反映）
+// 取り込み元のエントリーは残るため、必要に応じて呼び出し側で削除してください
+func (r *LeaderboardRepository) MergeUser(ctx context.Context, fromUserID, toUserID string) error {
+	_, err := r.db.NewRaw(`
+		INSERT INTO leaderboard_entries AS leaderboard_entry
+			(board, period, period_start, user_id, run_id, score, rank_value, achieved_at)
+		SELECT board, period, period_start, ?, run_id, score, rank_value, achieved_at
+		FROM leaderboard_entries
+		WHERE user_id = ?
+		ON CONFLICT (board, period, period_start, user_id) DO UPDATE
+		SET run_id = EXCLUDED.run_id,
+			score = EXCLUDED.score,
+			rank_value = EXCLUDED.rank_value,
+			achieved_at = EXCLUDED.achieved_at
+		WHERE leaderboard_entry.rank_value < EXCLUDED.rank_value`,
+		toUserID, fromUserID,
+	).Exec(ctx)
+	return err
+}
+
+// LockTimezone 日別・週別のエントリーを集計したタイムゾーンを行ロック付きで取得します（トランザクション内で使用）
+func (r *LeaderboardRepository) LockTimezone(ctx context.Context) (string, error) {
+	var timezone string
+	err := r.db.NewRaw("SELECT timezone FROM leaderboard_state WHERE id = 1 FOR UPDATE").Scan(ctx, &timezone)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil // Not Found
+		}
+		return "", err
+	}
+	return timezone, nil
+}
+
+// RebuildPeriods 日別・週別のエントリーを、指定したタイムゾーンで区切った期間でランから集計し直します
+// 集計方法は LeaderboardService の leaderboardScores と同じにしてください
+func (r *LeaderboardRepository) RebuildPeriods(ctx context.Context, timezone string) error {
+	_, err := r.db.NewRaw(`DELETE FROM leaderboard_entries WHERE period IN ('daily', 'weekly')`).Exec(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.NewRaw(`
+		INSERT INTO leaderboard_entries (board, period, period_start, user_id, run_id, score, rank_value, achieved_at)
+		SELECT DISTINCT ON (board, period, period_start, user_id)
+			board, period, period_start, user_id, run_id, score, rank_value, achieved_at
+		FROM (
+			SELECT r.id AS run_id, r.user_id, r.finished_at AS achieved_at, b.board, b.score, b.rank_value, p.period, p.period_start
+			FROM runs r
+			CROSS JOIN LATERAL (VALUES
+				('survival', r.survival_time, r.survival_time),
+				('kills', r.kill_count::double precision, r.kill_count::double precision),
+				('fastest_clear',
+					CASE WHEN r.cleared THEN EXTRACT(EPOCH FROM r.finished_at - r.started_at)::double precision END,
+					CASE WHEN r.cleared THEN -EXTRACT(EPOCH FROM r.finished_at - r.started_at)::double precision END)
+			) AS b (board, score, rank_value)
+			CROSS JOIN LATERAL (VALUES
+				('daily', (r.finished_at AT TIME ZONE ?0)::date),
+				('weekly', date_trunc('week', r.finished_at AT TIME ZONE ?0)::date)
+			) AS p (period, period_start)
+			WHERE r.status = 'finished' AND r.finished_at IS NOT NULL AND b.score IS NOT NULL
+		) AS candidates
+		ORDER BY board, period, period_start, user_id, rank_value DESC, achieved_at ASC`,
+		timezone,
+	).Exec(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.NewRaw(`
+		INSERT INTO leaderboard_state (id, timezone) VALUES (1, ?)
+		ON CONFLICT (id) DO UPDATE SET timezone = EXCLUDED.timezone`,
+		timezone,
+	).Exec(ctx)
+	return err
+}
+
+// FindAbove 指定したエントリーのすぐ上位のエントリーを近い順にlimit件取得します
+func (r *LeaderboardRepository) FindAbove(ctx context.Context, entry *entity.LeaderboardEntry, limit int) ([]entity.LeaderboardEntry, error) {
+	entries := []entity.LeaderboardEntry{}
+	err := r.boardQuery(&entries, entry.Board, entry.Period, entry.PeriodStart).
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.
+				Where("leaderboard_entry.rank_value > ?", entry.RankValue).
+				WhereOr("leaderboard_entry.rank_value = ? AND leaderboard_entry.achieved_at < ?", entry.RankValue, entry.AchievedAt)
+		}).
+		OrderExpr("leaderboard_entry.rank_value ASC, leaderboard_entry.achieved_at DESC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// FindBelow 指定したエントリーのすぐ下位のエントリーを近い順にlimit件取得します
+func (r *LeaderboardRepository) FindBelow(ctx context.Context, entry *entity.LeaderboardEntry, limit int) ([]entity.LeaderboardEntry, error) {
+	entries := []entity.LeaderboardEntry{}
+	err := r.boardQuery(&entries, entry.Board, entry.Period, entry.PeriodStart).
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.
+				Where("leaderboard_entry.rank_value < ?", entry.RankValue).
+				WhereOr("leaderboard_entry.rank_value = ? AND leaderboard_entry.achieved_at > ?", entry.RankValue, entry.AchievedAt)
+		}).
+		OrderExpr("leaderboard_entry.rank_value DESC, leaderboard_entry.achieved_at ASC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// CountBetter 指定した値より上位のエントリー数を数えます（順位 = 件数 + 1、削除申請中のユーザーを除く）
+func (r *LeaderboardRepository) CountBetter(ctx context.Context, board, period string, periodStart time.Time, rankValue float64) (int, error) {
+	return r.db.NewSelect().
+		Model((*entity.LeaderboardEntry)(nil)).
+		Where("board = ?", board).
+		Where("period = ?", period).
+		Where("period_start = ?", periodStart.Format(time.DateOnly)).
+		Where("rank_value > ?", rankValue).
+		Where("NOT EXISTS (SELECT 1 FROM users WHERE users.id = leaderboard_entry.user_id AND users.deleted_at IS NOT NULL)").
+		Count(ctx)
+}
+
+func (r *LeaderboardRepository) boardQuery(entries *[]entity.LeaderboardEntry, board, period string, periodStart time.Time) *bun.SelectQuery {
+	return r.db.NewSelect().
+		Model(entries).
+		Relation("User").
+		Where("leaderboard_entry.board = ?", board).
+		Where("leaderboard_entry.period = ?", period).
+		Where("leaderboard_entry.period_start = ?", periodStart.Format(time.DateOnly)).
+		// 削除申請中のユーザーはランキングに表示しない
+		Where(`"user".deleted_at IS NULL`)
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/uptrace/bun"
+)
+
+type LoadoutRepository struct {
+	db bun.IDB
+}
+
+func NewLoadoutRepository(db *bun.DB) *LoadoutRepository {
+	return &LoadoutRepository{db: db}
+}
+
+// WithTx トランザクション内で操作するリポジトリを返します
+func (r *LoadoutRepository) WithTx(tx bun.Tx) *LoadoutRepository {
+	return &LoadoutRepository{db: tx}
+}
+
+// FindByUserID ユーザーの装備プリセット一覧を取得します
+func (r *LoadoutRepository) FindByUserID(ctx context.Context, userID string) ([]entity.Loadout, error) {
+	loadouts := []entity.Loadout{}
+	err := r.db.NewSelect().
+		Model(&loadouts).
+		Where("user_id = ?", userID).
+		Order("created_at ASC", "id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return loadouts, nil
+}
+
+// FindByName 名前からユーザーの装備プリセットを取得します
+func (r *LoadoutRepository) FindByName(ctx context.Context, userID, name string) (*entity.Loadout, error) {
+	loadout := new(entity.Loadout)
+	err := r.db.NewSelect().
+		Model(loadout).
+		Where("user_id = ?", userID).
+		Where("name = ?", name).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Not Found
+		}
+		return nil, err
+	}
+	return loadout, nil
+}
+
+// CountByUserID ユーザーの装備プリセット数を数えます
+func (r *LoadoutRepository) CountByUserID(ctx context.Context, userID string) (int, error) {
+	return r.db.NewSelect().
+		Model((*entity.Loadout)(nil)).
+		Where("user_id = ?", userID).
+		Count(ctx)
+}
+
+// Upsert 装備プリセットを更新または新規登録します
+func (r *LoadoutRepository) Upsert(ctx context.Context, loadout *entity.Loadout) error {
+	_, err := r.db.NewInsert().
+		Model(loadout).
+		On("CONFLICT (user_id, name) DO UPDATE").
+		Set("weapon = EXCLUDED.weapon").
+		Set("special_type = EXCLUDED.special_type").
+		Set("consumables = EXCLUDED.consumables").
+		Set("updated_at = now()").
+		Returning("*").
+		Exec(ctx)
+	return err
+}
+
+// Delete 装備プリセットを削除します
+// 該当するプリセットがない場合はfalseを返します
+func (r *LoadoutRepository) Delete(ctx context.Context, userID, name string) (bool, error) {
+	res, err := r.db.NewDelete().
+		Model((*entity.Loadout)(nil)).
+		Where("user_id = ?", userID).
+		Where("name = ?", name).
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/uptrace/bun"
+)
+
+type MissionRepository struct {
+	db bun.IDB
+}
+
+func NewMissionRepository(db *bun.DB) *MissionRepository {
+	return &MissionRepository{db: db}
+}
+
+// WithTx トランザクション内で操作するリポジトリを返します
+func (r *MissionRepository) WithTx(tx bun.Tx) *MissionRepository {
+	return &MissionRepository{db: tx}
+}
+
+// FindActiveTemplates 指定した期間の有効なテンプレートを取得します
+func (r *MissionRepository) FindActiveTemplates(ctx context.Context, period string) ([]entity.MissionTemplate, error) {
+	templates := []entity.MissionTemplate{}
+	err := r.db.NewSelect().
+		Model(&templates).
+		Where("period = ?", period).
+		Where("is_active = ?", true).
+		Order("id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// CreateUserMissions ユーザーのミッションをまとめて登録します（登録済みのものは無視）
+func (r *MissionRepository) CreateUserMissions(ctx context.Context, missions []entity.UserMission) error {
+	if len(missions) == 0 {
+		return nil
+	}
+	_, err := r.db.NewInsert().
+		Model(&missions).
+		On("CONFLICT (user_id, template_id, period_start) DO NOTHING").
+		Exec(ctx)
+	return err
+}
+
+// CountUserMissions 指定した期間に割り当て済みのミッション数を数えます
+func (r *MissionRepository) CountUserMissions(ctx context.Context, userID, period string, periodStart time.Time) (int, error) {
+	return r.db.NewSelect().
+		Model((*entity.UserMission)(nil)).
+		Where("user_id = ?", userID).
+		Where("period = ?", period).
+		Where("period_start = ?", periodStart.Format(time.DateOnly)).
+		Count(ctx)
+}
+
+// FindUserMissions 指定した期間のユーザーのミッションを取得します（テンプレート情報も含む）
+func (r *MissionRepository) FindUserMissions(ctx context.Context, userID, period string, periodStart time.Time) ([]entity.UserMission, error) {
+	missions := []entity.UserMission{}
+	err := r.db.NewSelect().
+		Model(&missions).
+		Relation("Template").
+		Where("user_mission.user_id = ?", userID).
+		Where("user_mission.period = ?", period).
+		Where("user_mission.period_start = ?", periodStart.Format(time.DateOnly)).
+		Order("user_mission.id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return missions, nil
+}
+
+// FindUnclaimedExpired 期間が終了したが報酬を受け取っていない達成済みのミッションを取得します
+// expiredSince以降に期間が終了したものに限ります
+func (r *MissionRepository) FindUnclaimedExpired(ctx context.Context, userID string, expiredSince, now time.Time) ([]entity.UserMission, error) {
+	missions := []entity.UserMission{}
+	err := r.db.NewSelect().
+		Model(&missions).
+		Relation("Template").
+		Where("user_mission.user_id = ?", userID).
+		Where("user_mission.completed_at IS NOT NULL").
+		Where("user_mission.claimed_at IS NULL").
+		Where("user_mission.expires_at > ?", expiredSince).
+		Where("user_mission.expires_at <= ?", now).
+		Order("user_mission.expires_at ASC", "user_mission.id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return missions, nil
+}
+
+// FindUserMissionsForUpdate 指定した期間のユーザーのミッションを行ロック付きで取得します（トランザクション内で使用）
+func (r *MissionRepository) FindUserMissionsForUpdate(ctx context.Context, userID, period string, periodStart time.Time) ([]entity.UserMission, error) {
+	missions := []entity.UserMission{}
+	err := r.db.NewSelect().
+		Model(&missions).
+		Relation("Template").
+		Where("user_mission.user_id = ?", userID).
+		Where("user_mission.period = ?", period).
+		Where("user_mission.period_start = ?", periodStart.Format(time.DateOnly)).
+		Order("user_mission.id ASC").
+		For("UPDATE OF user_mission").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return missions, nil
+}
+
+// FindUserMissionForUpdate ユーザーのミッションを行ロック付きで取得します（トランザクション内で使用）
+func (r *MissionRepository) FindUserMissionForUpdate(ctx context.Context, id int64, userID string) (*entity.UserMission, error) {
+	mission := new(entity.UserMission)
+	err := r.db.NewSelect().
+		Model(mission).
+		Relation("Template").
+		Where("user_mission.id = ?", id).
+		Where("user_mission.user_id = ?", userID).
+		For("UPDATE OF user_mission").
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Not Found
+		}
+		return nil, err
+	}
+	return mission, nil
+}
+
+// UpdateProgress ミッションの進捗と達成日時を更新します
+func (r *MissionRepository) UpdateProgress(ctx context.Context, mission *entity.UserMission) error {
+	_, err := r.db.NewUpdate().
+		Model(mission).
+		Column("progress", "completed_at").
+		WherePK().
+		Exec(ctx)
+	return err
+}
+
+// MarkClaimed ミッションを報酬受け取り済みにします
+func (r *MissionRepository) MarkClaimed(ctx context.Context, mission *entity.UserMission) error {
+	_, err := r.db.NewUpdate().
+		Model(mission).
+		Column("claimed_at").
+		WherePK().
+		Exec(ctx)
+	return err
+}
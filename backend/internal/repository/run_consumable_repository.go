@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/uptrace/bun"
+)
+
+type RunConsumableRepository struct {
+	db bun.IDB
+}
+
+func NewRunConsumableRepository(db *bun.DB) *RunConsumableRepository {
+	return &RunConsumableRepository{db: db}
+}
+
+// WithTx トランザクション内で操作するリポジトリを返します
+func (r *RunConsumableRepository) WithTx(tx bun.Tx) *RunConsumableRepository {
+	return &RunConsumableRepository{db: tx}
+}
+
+// Create ランで消費したアイテムをまとめて登録します
+func (r *RunConsumableRepository) Create(ctx context.Context, consumables []entity.RunConsumable) error {
+	if len(consumables) == 0 {
+		return nil
+	}
+	_, err := r.db.NewInsert().
+		Model(&consumables).
+		Returning("*").
+		Exec(ctx)
+	return err
+}
+
+// FindByRunID ランで消費したアイテムを取得します
+func (r *RunConsumableRepository) FindByRunID(ctx context.Context, runID string) ([]entity.RunConsumable, error) {
+	consumables := []entity.RunConsumable{}
+	err := r.db.NewSelect().
+		Model(&consumables).
+		Where("run_id = ?", runID).
+		Order("item_id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return consumables, nil
+}
+
+// MarkRefunded ランで消費したアイテムを返却済みにします
+func (r *RunConsumableRepository) MarkRefunded(ctx context.Context, runID string, refundedAt time.Time) error {
+	_, err := r.db.NewUpdate().
+		Model((*entity.RunConsumable)(nil)).
+		Set("refunded_at = ?", refundedAt).
+		Where("run_id = ?", runID).
+		Where("refunded_at IS NULL").
+		Exec(ctx)
+	return err
+}
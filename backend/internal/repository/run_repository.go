@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/uptrace/bun"
+)
+
+// RunFilter ラン履歴の絞り込み条件
+type RunFilter struct {
+	UserID   string
+	Cleared  *bool     // nilの場合はクリア・失敗の両方
+	From     time.Time // ゼロ値の場合は指定なし（開始日時がこれ以降）
+	To       time.Time // ゼロ値の場合は指定なし（開始日時がこれより前）
+	BeforeID string    // 前ページ最後のランID（空の場合は先頭から）
+	Limit    int
+}
+
+type RunRepository struct {
+	db bun.IDB
+}
+
+func NewRunRepository(db *bun.DB) *RunRepository {
+	return &RunRepository{db: db}
+}
+
+// WithTx トランザクション内で操作するリポジトリを返します
+func (r *RunRepository) WithTx(tx bun.Tx) *RunRepository {
+	return &RunRepository{db: tx}
+}
+
+// Create ランを新規作成します
+func (r *RunRepository) Create(ctx context.Context, run *entity.Run) error {
+	_, err := r.db.NewInsert().
+		Model(run).
+		Returning("*").
+		Exec(ctx)
+	return err
+}
+
+// FindByIDForUpdate ユーザーのランを行ロック付きで取得します（トランザクション内で使用）
+func (r *RunRepository) FindByIDForUpdate(ctx context.Context, id, userID string) (*entity.Run, error) {
+	run := new(entity.Run)
+	err := r.db.NewSelect().
+		Model(run).
+		Where("id = ?", id).
+		Where("user_id = ?", userID).
+		For("UPDATE").
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Not Found
+		}
+		return nil, err
+	}
+	return run, nil
+}
+
+// UpdateResult ランの結果を保存します
+func (r *RunRepository) UpdateResult(ctx context.Context, run *entity.Run) error {
+	_, err := r.db.NewUpdate().
+		Model(run).
+		Column("status", "finished_at", "survival_time", "kill_count", "level", "coins", "weapons", "passives", "special_type", "cleared", "coin_reward").
+		WherePK().
+		Returning("*").
+		Exec(ctx)
+	return err
+}
+
+// FindCompleted 終了済みのランを新しい順に取得します
+func (r *RunRepository) FindCompleted(ctx context.Context, filter RunFilter) ([]entity.Run, error) {
+	runs := []entity.Run{}
+	q := r.db.NewSelect().
+		Model(&runs).
+		Where("user_id = ?", filter.UserID).
+		Where("status IN (?)", bun.In([]string{entity.RunStatusFinished, entity.RunStatusFlagged}))
+	if filter.Cleared != nil {
+		q = q.Where("cleared = ?", *filter.Cleared)
+	}
+	if !filter.From.IsZero() {
+		q = q.Where("started_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		q = q.Where("started_at < ?", filter.To)
+	}
+	if filter.BeforeID != "" {
+		// 開始日時とIDの組でページングする（同時刻のランがあっても重複・欠落しない）
+		q = q.Where("(started_at, id) < (SELECT started_at, id FROM runs WHERE id = ? AND user_id = ?)", filter.BeforeID, filter.UserID)
+	}
+	err := q.
+		Order("started_at DESC", "id DESC").
+		Limit(filter.Limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// ExistsCompleted 終了済みのランが存在するかを確認します
+func (r *RunRepository) ExistsCompleted(ctx context.Context, id, userID string) (bool, error) {
+	return r.db.NewSelect().
+		Model((*entity.Run)(nil)).
+		Where("id = ?", id).
+		Where("user_id = ?", userID).
+		Where("status IN (?)", bun.In([]string{entity.RunStatusFinished, entity.RunStatusFlagged})).
+		Exists(ctx)
+}
+
+// AggregateStats ユーザーの正常に終了したランを集計します
+func (r *RunRepository) AggregateStats(ctx context.Context, userID string) (*entity.CareerStats, error) {
+	stats := new(entity.CareerStats)
+	err := r.db.NewSelect().
+		Model((*entity.Run)(nil)).
+		ColumnExpr("COUNT(*) AS total_runs").
+		ColumnExpr("COUNT(*) FILTER (WHERE cleared) AS clears").
+		ColumnExpr("COUNT(*) FILTER (WHERE NOT cleared) AS failures").
+		ColumnExpr("COALESCE(SUM(survival_time), 0) AS total_play_time").
+		ColumnExpr("COALESCE(SUM(kill_count), 0) AS total_kills").
+		ColumnExpr("COALESCE(SUM(coin_reward), 0) AS total_coins_earned").
+		ColumnExpr("COALESCE(MAX(survival_time), 0) AS best_survival_time").
+		ColumnExpr("COALESCE(MAX(kill_count), 0) AS best_kill_count").
+		ColumnExpr("COALESCE(MAX(level), 0) AS best_level").
+		ColumnExpr("MIN(EXTRACT(EPOCH FROM finished_at - started_at)) FILTER (WHERE cleared) AS fastest_clear").
+		Where("user_id = ?", userID).
+		Where("status = ?", entity.RunStatusFinished).
+		Scan(ctx, stats)
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// FindMostPickedSkill 正常に終了したランで最も多く所持していたスキルの種類を取得します
+// column には "weapons" または "passives" を指定します。該当がない場合は空文字を返します
+func (r *RunRepository) FindMostPickedSkill(ctx context.Context, userID, column string) (string, error) {
+	var skillType string
+	err := r.db.NewSelect().
+		TableExpr("runs").
+		TableExpr("jsonb_array_elements(runs.?) AS skill", bun.Ident(column)).
+		ColumnExpr("skill->>'type' AS skill_type").
+		Where("runs.user_id = ?", userID).
+		Where("runs.status = ?", entity.RunStatusFinished).
+		GroupExpr("skill_type").
+		OrderExpr("COUNT(*) DESC, skill_type ASC").
+		Limit(1).
+		Scan(ctx, &skillType)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", err
+	}
+	return skillType, nil
+}
+
+// CountClearsWithOnlyWeapon 指定した武器のみを所持してクリアしたランの数を数えます
+func (r *RunRepository) CountClearsWithOnlyWeapon(ctx context.Context, userID, weapon string) (int, error) {
+	return r.db.NewSelect().
+		Model((*entity.Run)(nil)).
+		Where("user_id = ?", userID).
+		Where("status = ?", entity.RunStatusFinished).
+		Where("cleared = ?", true).
+		Where("jsonb_array_length(weapons) > 0").
+		Where("NOT EXISTS (SELECT 1 FROM jsonb_array_elements(weapons) AS w WHERE w->>'type' <> ?)", weapon).
+		Count(ctx)
+}
+
+// ReassignUser ランの所有者を別のユーザーに付け替え、件数を返します
+func (r *RunRepository) ReassignUser(ctx context.Context, fromUserID, toUserID string) (int, error) {
+	res, err := r.db.NewUpdate().
+		Model((*entity.Run)(nil)).
+		Set("user_id = ?", toUserID).
+		Where("user_id = ?", fromUserID).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/uptrace/bun"
+)
+
+type RunReviewRepository struct {
+	db bun.IDB
+}
+
+func NewRunReviewRepository(db *bun.DB) *RunReviewRepository {
+	return &RunReviewRepository{db: db}
+}
+
+// WithTx トランザクション内で操作するリポジトリを返します
+func (r *RunReviewRepository) WithTx(tx bun.Tx) *RunReviewRepository {
+	return &RunReviewRepository{db: tx}
+}
+
+// Create 審査待ちのレコードを作成します
+func (r *RunReviewRepository) Create(ctx context.Context, review *entity.RunReview) error {
+	_, err := r.db.NewInsert().
+		Model(review).
+		Returning("*").
+		Exec(ctx)
+	return err
+}
+
+// ReassignUser 審査レコードの所有者を別のユーザーに付け替えます
+func (r *RunReviewRepository) ReassignUser(ctx context.Context, fromUserID, toUserID string) error {
+	_, err := r.db.NewUpdate().
+		Model((*entity.RunReview)(nil)).
+		Set("user_id = ?", toUserID).
+		Where("user_id = ?", fromUserID).
+		Exec(ctx)
+	return err
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/uptrace/bun"
+)
+
+type SettingsProfileRepository struct {
+	db bun.IDB
+}
+
+func NewSettingsProfileRepository(db *bun.DB) *SettingsProfileRepository {
+	return &SettingsProfileRepository{db: db}
+}
+
+// WithTx トランザクション内で操作するリポジトリを返します
+func (r *SettingsProfileRepository) WithTx(tx bun.Tx) *SettingsProfileRepository {
+	return &SettingsProfileRepository{db: tx}
+}
+
+// FindByUserID ユーザーの端末ごとの設定一覧を取得します
+func (r *SettingsProfileRepository) FindByUserID(ctx context.Context, userID string) ([]entity.SettingsProfile, error) {
+	profiles := []entity.SettingsProfile{}
+	err := r.db.NewSelect().
+		Model(&profiles).
+		Where("user_id = ?", userID).
+		Order("created_at ASC", "id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// FindByDeviceID 端末の識別子からユーザーの設定を取得します
+func (r *SettingsProfileRepository) FindByDeviceID(ctx context.Context, userID, deviceID string) (*entity.SettingsProfile, error) {
+	profile := new(entity.SettingsProfile)
+	err := r.db.NewSelect().
+		Model(profile).
+		Where("user_id = ?", userID).
+		Where("device_id = ?", deviceID).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Not Found
+		}
+		return nil, err
+	}
+	return profile, nil
+}
+
+// CountByUserID ユーザーの端末ごとの設定の数を数えます
+func (r *SettingsProfileRepository) CountByUserID(ctx context.Context, userID string) (int, error) {
+	return r.db.NewSelect().
+		Model((*entity.SettingsProfile)(nil)).
+		Where("user_id = ?", userID).
+		Count(ctx)
+}
+
+// Upsert 端末ごとの設定を更新または新規登録します
+func (r *SettingsProfileRepository) Upsert(ctx context.Context, profile *entity.SettingsProfile) error {
+	_, err := r.db.NewInsert().
+		Model(profile).
+		On("CONFLICT (user_id, device_id) DO UPDATE").
+		Set("name = EXCLUDED.name").
+		Set("overrides = EXCLUDED.overrides").
+		Set("updated_at = now()").
+		Returning("*").
+		Exec(ctx)
+	return err
+}
+
+// Delete 端末ごとの設定を削除します
+// 該当する設定がない場合はfalseを返します
+func (r *SettingsProfileRepository) Delete(ctx context.Context, userID, deviceID string) (bool, error) {
+	res, err := r.db.NewDelete().
+		Model((*entity.SettingsProfile)(nil)).
+		Where("user_id = ?", userID).
+		Where("device_id = ?", deviceID).
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
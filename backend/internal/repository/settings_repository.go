@@ -4,19 +4,25 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
 	"github.com/uptrace/bun"
 )
 
 type SettingsRepository struct {
-	db *bun.DB
+	db bun.IDB
 }
 
 func NewSettingsRepository(db *bun.DB) *SettingsRepository {
 	return &SettingsRepository{db: db}
 }
 
+// WithTx トランザクション内で操作するリポジトリを返します
+func (r *SettingsRepository) WithTx(tx bun.Tx) *SettingsRepository {
+	return &SettingsRepository{db: tx}
+}
+
 // GetByUserID ユーザーIDから設定を取得します
 func (r *SettingsRepository) GetByUserID(ctx context.Context, userID string) (*entity.Settings, error) {
 	settings := new(entity.Settings)
@@ -41,8 +47,51 @@ func (r *SettingsRepository) Upsert(ctx context.Context, settings *entity.Settin
 		On("CONFLICT (user_id) DO UPDATE").
 		Set("bgm_volume = EXCLUDED.bgm_volume").
 		Set("se_volume = EXCLUDED.se_volume").
+		Set("preferences = EXCLUDED.preferences").
 		Set("updated_at = now()").
 		Returning("*").
 		Exec(ctx)
 	return err
 }
+
+// CreateIfNotExists 設定がまだ保存されていない場合のみ作成します
+// 既に存在する場合はfalseを返します
+func (r *SettingsRepository) CreateIfNotExists(ctx context.Context, settings *entity.Settings) (bool, error) {
+	res, err := r.db.NewInsert().
+		Model(settings).
+		// 0を指定した場合もDEFAULTにならないよう明示する
+		Value("bgm_volume", "?", settings.BGMVolume).
+		Value("se_volume", "?", settings.SEVolume).
+		On("CONFLICT (user_id) DO NOTHING").
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// UpdateIfUnmodified 設定がupdatedAtの時点から更新されていない場合のみ更新します
+// 他のリクエストが先に更新していた場合はfalseを返します
+func (r *SettingsRepository) UpdateIfUnmodified(ctx context.Context, settings *entity.Settings, updatedAt time.Time) (bool, error) {
+	res, err := r.db.NewUpdate().
+		Model(settings).
+		Column("bgm_volume", "se_volume", "preferences").
+		Set("updated_at = now()").
+		Where("user_id = ?", settings.UserID).
+		Where("updated_at = ?", updatedAt).
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/uptrace/bun"
+)
+
+type ShopAuditLogRepository struct {
+	db bun.IDB
+}
+
+func NewShopAuditLogRepository(db *bun.DB) *ShopAuditLogRepository {
+	return &ShopAuditLogRepository{db: db}
+}
+
+// WithTx トランザクション内で操作するリポジトリを返します
+func (r *ShopAuditLogRepository) WithTx(tx bun.Tx) *ShopAuditLogRepository {
+	return &ShopAuditLogRepository{db: tx}
+}
+
+// Create 変更履歴を登録します
+func (r *ShopAuditLogRepository) Create(ctx context.Context, log *entity.ShopAuditLog) error {
+	_, err := r.db.NewInsert().
+		Model(log).
+		Returning("*").
+		Exec(ctx)
+	return err
+}
+
+// FindByItemID アイテムの変更履歴を新しい順に取得します
+// beforeIDが0より大きい場合は、そのIDより前の履歴を取得します
+func (r *ShopAuditLogRepository) FindByItemID(ctx context.Context, itemID int, beforeID int64, limit int) ([]entity.ShopAuditLog, error) {
+	logs := []entity.ShopAuditLog{}
+	q := r.db.NewSelect().
+		Model(&logs).
+		Where("item_id = ?", itemID)
+	if beforeID > 0 {
+		q = q.Where("id < ?", beforeID)
+	}
+	err := q.Order("id DESC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
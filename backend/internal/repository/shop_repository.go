@@ -10,20 +10,25 @@ import (
 )
 
 type ShopRepository struct {
-	db *bun.DB
+	db bun.IDB
 }
 
 func NewShopRepository(db *bun.DB) *ShopRepository {
 	return &ShopRepository{db: db}
 }
 
+// WithTx トランザクション内で操作するリポジトリを返します
+func (r *ShopRepository) WithTx(tx bun.Tx) *ShopRepository {
+	return &ShopRepository{db: tx}
+}
+
 // FindAll アクティブな全アイテムを取得します
 func (r *ShopRepository) FindAll(ctx context.Context) ([]entity.Shop, error) {
 	shops := []entity.Shop{}
 	err := r.db.NewSelect().
 		Model(&shops).
 		Where("is_active = ?", true).
-		Order("price ASC").
+		Order("sort_order ASC", "price ASC").
 		Scan(ctx)
 	if err != nil {
 		return nil, err
@@ -47,3 +52,92 @@ func (r *ShopRepository) FindByID(ctx context.Context, id int) (*entity.Shop, er
 	}
 	return shop, nil
 }
+
+// FindByIDIncludingInactive 販売停止中のものも含めてIDからアイテムを取得します
+func (r *ShopRepository) FindByIDIncludingInactive(ctx context.Context, id int) (*entity.Shop, error) {
+	shop := new(entity.Shop)
+	err := r.db.NewSelect().
+		Model(shop).
+		Where("item_id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Not Found
+		}
+		return nil, err
+	}
+	return shop, nil
+}
+
+// FindAllIncludingInactive 販売停止中のものも含めて全アイテムを表示順に取得します
+func (r *ShopRepository) FindAllIncludingInactive(ctx context.Context) ([]entity.Shop, error) {
+	shops := []entity.Shop{}
+	err := r.db.NewSelect().
+		Model(&shops).
+		Order("sort_order ASC", "item_id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return shops, nil
+}
+
+// FindAllIncludingInactiveForUpdate 販売停止中のものも含めて全アイテムを行ロック付きで表示順に取得します（トランザクション内で使用）
+func (r *ShopRepository) FindAllIncludingInactiveForUpdate(ctx context.Context) ([]entity.Shop, error) {
+	shops := []entity.Shop{}
+	err := r.db.NewSelect().
+		Model(&shops).
+		Order("sort_order ASC", "item_id ASC").
+		For("UPDATE").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return shops, nil
+}
+
+// LockCatalog 商品の追加と並び替えが同時に行われないよう、トランザクションの終了までロックします
+// 行ロックでは追加される商品を防げないため、アドバイザリロックを使います
+func (r *ShopRepository) LockCatalog(ctx context.Context) error {
+	_, err := r.db.NewRaw("SELECT pg_advisory_xact_lock(hashtext('shop_catalog'))").Exec(ctx)
+	return err
+}
+
+// FindByIDForUpdate IDからアイテムを行ロック付きで取得します（トランザクション内で使用）
+func (r *ShopRepository) FindByIDForUpdate(ctx context.Context, id int) (*entity.Shop, error) {
+	shop := new(entity.Shop)
+	err := r.db.NewSelect().
+		Model(shop).
+		Where("item_id = ?", id).
+		For("UPDATE").
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Not Found
+		}
+		return nil, err
+	}
+	return shop, nil
+}
+
+// Create アイテムを新規登録します
+func (r *ShopRepository) Create(ctx context.Context, shop *entity.Shop) error {
+	_, err := r.db.NewInsert().
+		Model(shop).
+		// is_activeはdefault指定のため、falseを明示しないとtrueで登録されてしまう
+		Value("is_active", "?", shop.IsActive).
+		Returning("*").
+		Exec(ctx)
+	return err
+}
+
+// Update アイテムの指定したカラムを更新します
+func (r *ShopRepository) Update(ctx context.Context, shop *entity.Shop, columns ...string) error {
+	_, err := r.db.NewUpdate().
+		Model(shop).
+		Column(columns...).
+		WherePK().
+		Returning("*").
+		Exec(ctx)
+	return err
+}
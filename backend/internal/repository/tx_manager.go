@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+)
+
+type TxManager struct {
+	db *bun.DB
+}
+
+func NewTxManager(db *bun.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// RunInTx fnを1つのトランザクション内で実行します
+// fnがエラーを返した場合はロールバックされます
+func (m *TxManager) RunInTx(ctx context.Context, fn func(ctx context.Context, tx bun.Tx) error) error {
+	return m.db.RunInTx(ctx, nil, fn)
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/uptrace/bun"
+)
+
+type UnlockableRepository struct {
+	db bun.IDB
+}
+
+func NewUnlockableRepository(db *bun.DB) *UnlockableRepository {
+	return &UnlockableRepository{db: db}
+}
+
+// WithTx トランザクション内で操作するリポジトリを返します
+func (r *UnlockableRepository) WithTx(tx bun.Tx) *UnlockableRepository {
+	return &UnlockableRepository{db: tx}
+}
+
+// FindActive 有効な解放対象の一覧を種類・表示順に取得します
+func (r *UnlockableRepository) FindActive(ctx context.Context) ([]entity.Unlockable, error) {
+	unlockables := []entity.Unlockable{}
+	err := r.db.NewSelect().
+		Model(&unlockables).
+		Where("is_active = ?", true).
+		Order("kind DESC", "sort_order ASC", "id ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return unlockables, nil
+}
+
+// FindByCode コードから有効な解放対象を取得します
+func (r *UnlockableRepository) FindByCode(ctx context.Context, code string) (*entity.Unlockable, error) {
+	unlockable := new(entity.Unlockable)
+	err := r.db.NewSelect().
+		Model(unlockable).
+		Where("code = ?", code).
+		Where("is_active = ?", true).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Not Found
+		}
+		return nil, err
+	}
+	return unlockable, nil
+}
+
+// FindUnlockedByUserID ユーザーが解放済みの一覧を取得します
+func (r *UnlockableRepository) FindUnlockedByUserID(ctx context.Context, userID string) ([]entity.UserUnlock, error) {
+	unlocked := []entity.UserUnlock{}
+	err := r.db.NewSelect().
+		Model(&unlocked).
+		Where("user_id = ?", userID).
+		Order("unlocked_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return unlocked, nil
+}
+
+// Unlock 解放済みとして登録します
+// 既に解放済みの場合はfalseを返します
+func (r *UnlockableRepository) Unlock(ctx context.Context, uu *entity.UserUnlock) (bool, error) {
+	res, err := r.db.NewInsert().
+		Model(uu).
+		On("CONFLICT (user_id, unlockable_id) DO NOTHING").
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
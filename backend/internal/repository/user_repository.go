@@ -4,19 +4,25 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
 	"github.com/uptrace/bun"
 )
 
 type UserRepository struct {
-	db *bun.DB
+	db bun.IDB
 }
 
 func NewUserRepository(db *bun.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
+// WithTx トランザクション内で操作するリポジトリを返します
+func (r *UserRepository) WithTx(tx bun.Tx) *UserRepository {
+	return &UserRepository{db: tx}
+}
+
 // CreateUser ユーザーを作成または更新します (UPSERT)
 // IDが既に存在する場合は、Name, Email, AvatarURLを更新します
 func (r *UserRepository) CreateUser(ctx context.Context, user *entity.User) error {
@@ -32,24 +38,39 @@ func (r *UserRepository) CreateUser(ctx context.Context, user *entity.User) erro
 	return err
 }
 
-// UpdateCoin ユーザーのコインを加算または減算します
-func (r *UserRepository) UpdateCoin(ctx context.Context, userID string, amount int) error {
-	res, err := r.db.NewUpdate().
+// UpdateCoin ユーザーのコインを加算または減算し、更新後の残高を返します
+func (r *UserRepository) UpdateCoin(ctx context.Context, userID string, amount int) (int, error) {
+	var coin int
+	err := r.db.NewUpdate().
 		Table("users").
 		Set("coin = coin + ?", amount).
 		Where("id = ?", userID).
-		Exec(ctx)
+		Returning("coin").
+		Scan(ctx, &coin)
 	if err != nil {
-		return err
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, errors.New("user not found")
+		}
+		return 0, err
 	}
-	rows, err := res.RowsAffected()
+	return coin, nil
+}
+
+// FindByIDForUpdate IDからユーザーを行ロック付きで取得します（トランザクション内で使用）
+func (r *UserRepository) FindByIDForUpdate(ctx context.Context, id string) (*entity.User, error) {
+	user := new(entity.User)
+	err := r.db.NewSelect().
+		Model(user).
+		Where("id = ?", id).
+		For("UPDATE").
+		Scan(ctx)
 	if err != nil {
-		return err
-	}
-	if rows == 0 {
-		return errors.New("user not found")
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Not Found
+		}
+		return nil, err
 	}
-	return nil
+	return user, nil
 }
 
 // FindByID IDからユーザーを取得します
@@ -67,3 +88,140 @@ func (r *UserRepository) FindByID(ctx context.Context, id string) (*entity.User,
 	}
 	return user, nil
 }
+
+// Delete ユーザーを削除します（関連するデータはカスケード削除されます）
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.NewDelete().
+		Model((*entity.User)(nil)).
+		Where("id = ?", id).
+		Exec(ctx)
+	return err
+}
+
+// ScheduleDeletion アカウントを削除申請済みにします（申請済みの場合は最初の申請日時を維持します）
+func (r *UserRepository) ScheduleDeletion(ctx context.Context, id string, purgeAt time.Time) (*entity.User, error) {
+	user := new(entity.User)
+	err := r.db.NewUpdate().
+		Model(user).
+		Set("deleted_at = COALESCE(deleted_at, now())").
+		Set("purge_at = COALESCE(purge_at, ?)", purgeAt).
+		Where("id = ?", id).
+		Returning("*").
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Not Found
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// CancelDeletion アカウントの削除申請を取り消します
+// 削除申請されていない場合はfalseを返します
+func (r *UserRepository) CancelDeletion(ctx context.Context, id string) (bool, error) {
+	res, err := r.db.NewUpdate().
+		Model((*entity.User)(nil)).
+		Set("deleted_at = NULL").
+		Set("purge_at = NULL").
+		Where("id = ?", id).
+		Where("deleted_at IS NOT NULL").
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// IsPendingDeletion アカウントが削除申請済みかを確認します（ユーザーが存在しない場合はfalseを返します）
+func (r *UserRepository) IsPendingDeletion(ctx context.Context, id string) (bool, error) {
+	return r.db.NewSelect().
+		Model((*entity.User)(nil)).
+		Where("id = ?", id).
+		Where("deleted_at IS NOT NULL").
+		Exists(ctx)
+}
+
+// DeletePurgeable 猶予期間が過ぎたアカウントを削除し、件数を返します
+func (r *UserRepository) DeletePurgeable(ctx context.Context, now time.Time) (int, error) {
+	res, err := r.db.NewDelete().
+		Model((*entity.User)(nil)).
+		Where("purge_at <= ?", now).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+// TouchGuest ゲストの最終利用日時（updated_at）を更新します
+// ゲストが存在しない場合はfalseを返します
+func (r *UserRepository) TouchGuest(ctx context.Context, id string) (bool, error) {
+	res, err := r.db.NewUpdate().
+		Model((*entity.User)(nil)).
+		Set("updated_at = now()").
+		Where("id = ?", id).
+		Where("is_guest").
+		Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// DeleteStaleGuests before以降に利用されていないゲストを削除し、件数を返します
+// ユーザー行の更新（コインの増減など）とランの開始を利用とみなします
+func (r *UserRepository) DeleteStaleGuests(ctx context.Context, before time.Time) (int, error) {
+	res, err := r.db.NewDelete().
+		Model((*entity.User)(nil)).
+		Where("is_guest").
+		Where("updated_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM runs WHERE runs.user_id = ?TableAlias.id AND runs.started_at >= ?)", before).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+// UpdateProfile 表示名とアバターを更新します
+func (r *UserRepository) UpdateProfile(ctx context.Context, user *entity.User) error {
+	_, err := r.db.NewUpdate().
+		Model(user).
+		Column("display_name", "avatar", "display_name_changed_at").
+		WherePK().
+		Returning("*").
+		Exec(ctx)
+	return err
+}
+
+// ExistsDisplayName 他のユーザーが同じ表示名を使っているかを確認します（大文字・小文字は区別しません）
+func (r *UserRepository) ExistsDisplayName(ctx context.Context, displayName, excludeUserID string) (bool, error) {
+	return r.db.NewSelect().
+		Model((*entity.User)(nil)).
+		Where("lower(display_name) = lower(?)", displayName).
+		Where("id <> ?", excludeUserID).
+		Exists(ctx)
+}
+
+// LockDisplayName 同じ表示名への変更が同時に行われないよう、トランザクションの終了までロックします
+func (r *UserRepository) LockDisplayName(ctx context.Context, displayName string) error {
+	_, err := r.db.NewRaw("SELECT pg_advisory_xact_lock(hashtext(lower(?)))", displayName).Exec(ctx)
+	return err
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/uptrace/bun"
+)
+
+type UserRoleRepository struct {
+	db bun.IDB
+}
+
+func NewUserRoleRepository(db *bun.DB) *UserRoleRepository {
+	return &UserRoleRepository{db: db}
+}
+
+// WithTx トランザクション内で操作するリポジトリを返します
+func (r *UserRoleRepository) WithTx(tx bun.Tx) *UserRoleRepository {
+	return &UserRoleRepository{db: tx}
+}
+
+// FindRolesByUserID ユーザーに付与された権限の一覧を取得します
+func (r *UserRoleRepository) FindRolesByUserID(ctx context.Context, userID string) ([]string, error) {
+	roles := []string{}
+	err := r.db.NewSelect().
+		Model((*entity.UserRole)(nil)).
+		Column("role").
+		Where("user_id = ?", userID).
+		Order("role ASC").
+		Scan(ctx, &roles)
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
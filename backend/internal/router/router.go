@@ -2,13 +2,17 @@ package router
 
 import (
 	"net/http"
+	"time"
 
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
 	"github.com/RiTa-23/TRI-Survivor/backend/internal/handler"
 	userMiddleware "github.com/RiTa-23/TRI-Survivor/backend/internal/middleware"
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/repository"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
 )
 
-func SetupRouter(e *echo.Echo, userHandler *handler.UserHandler, settingsHandler *handler.SettingsHandler, shopHandler *handler.ShopHandler, itemHandler *handler.ItemHandler) {
+func SetupRouter(e *echo.Echo, userHandler *handler.UserHandler, settingsHandler *handler.SettingsHandler, shopHandler *handler.ShopHandler, itemHandler *handler.ItemHandler, runHandler *handler.RunHandler, leaderboardHandler *handler.LeaderboardHandler, achievementHandler *handler.AchievementHandler, missionHandler *handler.MissionHandler, loadoutHandler *handler.LoadoutHandler, unlockHandler *handler.UnlockHandler, adminShopHandler *handler.AdminShopHandler, idempotencyRepo *repository.IdempotencyRepository, userRepo *repository.UserRepository, userRoleRepo *repository.UserRoleRepository, verifier userMiddleware.TokenVerifier, healthHandler *handler.HealthHandler, guestHandler *handler.GuestHandler, accountHandler *handler.AccountHandler, profileHandler *handler.ProfileHandler) {
 	api := e.Group("/api")
 
 	// パブリックルート
@@ -17,22 +21,96 @@ func SetupRouter(e *echo.Echo, userHandler *handler.UserHandler, settingsHandler
 			"status": "ok",
 		})
 	})
+	api.GET("/ready", healthHandler.Ready)
+
+	// ゲストアカウントの作成（未ログインで呼べるため、IPごとに作成頻度を制限する）
+	guestLimiter := middleware.RateLimiterWithConfig(middleware.RateLimiterConfig{
+		Store: middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+			Rate:      1.0 / 60,
+			Burst:     5,
+			ExpiresIn: 10 * time.Minute,
+		}),
+	})
+	api.POST("/guest", guestHandler.CreateGuest, guestLimiter)
 
 	// 認証付きルート (v1)
+	auth := userMiddleware.AuthMiddleware(verifier)
 	v1 := api.Group("/v1")
-	v1.Use(userMiddleware.AuthMiddleware())
+	v1.Use(auth)
 
-	v1.POST("/users", userHandler.SyncUser)
+	// 削除を申請したアカウントは、猶予期間中も削除の取り消し・データのエクスポート・ユーザー情報の取得のみ行える
 	v1.GET("/users/me", userHandler.GetMe)
-	v1.POST("/users/me/coins", userHandler.AddCoin)
+	v1.POST("/users/me/restore", accountHandler.RestoreMe)
+	v1.GET("/users/me/export", accountHandler.ExportMe)
+
+	rejectPendingDeletion := userMiddleware.RejectPendingDeletion(userRepo)
+	active := v1.Group("", rejectPendingDeletion)
+
+	// 重複実行を防ぐため、更新系のルートにはIdempotency-Keyを適用する
+	idempotency := userMiddleware.IdempotencyMiddleware(idempotencyRepo)
+
+	active.POST("/users", userHandler.SyncUser)
+	active.PATCH("/users/me", profileHandler.UpdateMe)
+	active.GET("/players/:id", profileHandler.GetPlayer)
+	active.DELETE("/users/me", accountHandler.DeleteMe)
+	active.POST("/users/me/merge", guestHandler.MergeGuest, idempotency)
+	active.POST("/users/me/guest-token", guestHandler.RefreshGuestToken)
+	active.GET("/users/me/coins/history", userHandler.GetCoinHistory)
+	active.GET("/users/me/runs", runHandler.ListMyRuns)
+	active.GET("/users/me/stats", runHandler.GetMyStats)
+	active.GET("/users/me/loadout", loadoutHandler.GetMyLoadout)
+	active.GET("/users/me/loadouts", loadoutHandler.GetMyLoadouts)
+	active.PUT("/users/me/loadouts/:name", loadoutHandler.SaveMyLoadout, idempotency)
+	active.DELETE("/users/me/loadouts/:name", loadoutHandler.DeleteMyLoadout)
+	active.GET("/users/me/unlocks", unlockHandler.GetMyUnlocks)
 
 	// Settings
-	v1.GET("/settings", settingsHandler.GetSettings)
-	v1.PUT("/settings", settingsHandler.UpdateSettings)
+	active.GET("/settings", settingsHandler.GetSettings)
+	active.PUT("/settings", settingsHandler.UpdateSettings, idempotency)
+	active.PATCH("/settings", settingsHandler.PatchSettings, idempotency)
+	active.GET("/settings/effective", settingsHandler.GetEffectiveSettings)
+	active.GET("/settings/profiles", settingsHandler.GetSettingsProfiles)
+	active.PUT("/settings/profiles/:deviceId", settingsHandler.SaveSettingsProfile, idempotency)
+	active.DELETE("/settings/profiles/:deviceId", settingsHandler.DeleteSettingsProfile)
 
 	// Shop
-	v1.GET("/shop", shopHandler.GetShopItems)
-	v1.GET("/shop/:id", shopHandler.GetShopItemByID)
+	active.GET("/shop", shopHandler.GetShopItems)
+	active.GET("/shop/:id", shopHandler.GetShopItemByID)
+	active.POST("/shop/:id/purchase", shopHandler.PurchaseShopItem, idempotency)
+	active.POST("/shop/:id/upgrade", shopHandler.UpgradeShopItem, idempotency)
 	// Items
-	v1.GET("/items", itemHandler.GetUserItems)
+	active.GET("/items", itemHandler.GetUserItems)
+
+	// Runs
+	active.POST("/runs", runHandler.StartRun, idempotency)
+	active.POST("/runs/:id/finish", runHandler.FinishRun, idempotency)
+	active.POST("/runs/:id/abort", runHandler.AbortRun, idempotency)
+
+	// Leaderboards
+	active.GET("/leaderboards/:board", leaderboardHandler.GetLeaderboard)
+
+	// Achievements
+	active.GET("/achievements", achievementHandler.GetAchievements)
+
+	// Missions
+	active.GET("/missions", missionHandler.GetMissions)
+	active.POST("/missions/:id/claim", missionHandler.ClaimMission, idempotency)
+
+	// Unlockables
+	active.GET("/unlockables", unlockHandler.GetUnlockables)
+	active.POST("/unlockables/:code/unlock", unlockHandler.PurchaseUnlock, idempotency)
+
+	// 管理者用ルート（閲覧はモデレーター以上、変更は管理者のみ）
+	admin := api.Group("/admin")
+	admin.Use(auth, rejectPendingDeletion, userMiddleware.LoadRoles(userRoleRepo), userMiddleware.RequireRole(entity.RoleAdmin, entity.RoleModerator))
+	adminOnly := userMiddleware.RequireRole(entity.RoleAdmin)
+
+	admin.GET("/shop", adminShopHandler.ListItems)
+	admin.POST("/shop", adminShopHandler.CreateItem, adminOnly, idempotency)
+	admin.PUT("/shop/order", adminShopHandler.ReorderItems, adminOnly)
+	admin.PUT("/shop/:id", adminShopHandler.UpdateItem, adminOnly)
+	admin.PUT("/shop/:id/price", adminShopHandler.ChangePrice, adminOnly)
+	admin.POST("/shop/:id/activate", adminShopHandler.ActivateItem, adminOnly)
+	admin.POST("/shop/:id/deactivate", adminShopHandler.DeactivateItem, adminOnly)
+	admin.GET("/shop/:id/audit-logs", adminShopHandler.GetAuditLogs)
 }
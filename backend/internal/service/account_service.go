@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/repository"
+)
+
+// exportPageSize エクスポート時に台帳・ラン履歴を読み込む1回あたりの件数
+const exportPageSize = 500
+
+var ErrAccountNotScheduledForDeletion = errors.New("account is not scheduled for deletion")
+
+// AccountConfig アカウント削除の猶予期間と、完全に削除する処理の実行間隔
+type AccountConfig struct {
+	DeletionGracePeriod time.Duration
+	PurgeInterval       time.Duration
+	GuestRetention      time.Duration // 引き継がれずに利用されなくなったゲストを削除するまでの期間
+}
+
+// LoadAccountConfig 環境変数から設定を読み込む（未設定の項目はデフォルト値）
+func LoadAccountConfig() AccountConfig {
+	return AccountConfig{
+		DeletionGracePeriod: envDuration("ACCOUNT_DELETION_GRACE_PERIOD", 30*24*time.Hour),
+		PurgeInterval:       envDuration("ACCOUNT_PURGE_INTERVAL", time.Hour),
+		// ゲストのトークンが失効するまでは利用される可能性があるため、トークンの有効期間と同じにする
+		GuestRetention: envDuration("GUEST_TOKEN_TTL", 30*24*time.Hour),
+	}
+}
+
+type AccountService struct {
+	userRepo        *repository.UserRepository
+	settingsRepo    *repository.SettingsRepository
+	profileRepo     *repository.SettingsProfileRepository
+	itemRepo        *repository.ItemRepository
+	coinTxRepo      *repository.CoinTransactionRepository
+	runRepo         *repository.RunRepository
+	achievementRepo *repository.AchievementRepository
+	unlockableRepo  *repository.UnlockableRepository
+	loadoutRepo     *repository.LoadoutRepository
+	config          AccountConfig
+}
+
+func NewAccountService(userRepo *repository.UserRepository, settingsRepo *repository.SettingsRepository, profileRepo *repository.SettingsProfileRepository, itemRepo *repository.ItemRepository, coinTxRepo *repository.CoinTransactionRepository, runRepo *repository.RunRepository, achievementRepo *repository.AchievementRepository, unlockableRepo *repository.UnlockableRepository, loadoutRepo *repository.LoadoutRepository, config AccountConfig) *AccountService {
+	return &AccountService{
+		userRepo:        userRepo,
+		settingsRepo:    settingsRepo,
+		profileRepo:     profileRepo,
+		itemRepo:        itemRepo,
+		coinTxRepo:      coinTxRepo,
+		runRepo:         runRepo,
+		achievementRepo: achievementRepo,
+		unlockableRepo:  unlockableRepo,
+		loadoutRepo:     loadoutRepo,
+		config:          config,
+	}
+}
+
+// RequestDeletion アカウントの削除を申請する（猶予期間内であればRestoreで取り消せる）
+func (s *AccountService) RequestDeletion(ctx context.Context, userID string) (*entity.User, error) {
+	user, err := s.userRepo.ScheduleDeletion(ctx, userID, time.Now().Add(s.config.DeletionGracePeriod))
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// Restore 猶予期間内のアカウントの削除申請を取り消す
+func (s *AccountService) Restore(ctx context.Context, userID string) (*entity.User, error) {
+	ok, err := s.userRepo.CancelDeletion(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		user, err := s.userRepo.FindByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrAccountNotScheduledForDeletion
+	}
+	return s.userRepo.FindByID(ctx, userID)
+}
+
+// PurgeExpired 猶予期間が過ぎたアカウントを完全に削除する（関連するデータはカスケード削除される）
+func (s *AccountService) PurgeExpired(ctx context.Context) (int, error) {
+	return s.userRepo.DeletePurgeable(ctx, time.Now())
+}
+
+// PurgeStaleGuests ゲストのトークンの有効期間より長く利用されていないゲストを削除する
+// POST /api/guestは未ログインで呼べるため、引き継がれなかったゲストが溜まり続けないようにする
+func (s *AccountService) PurgeStaleGuests(ctx context.Context) (int, error) {
+	return s.userRepo.DeleteStaleGuests(ctx, time.Now().Add(-s.config.GuestRetention))
+}
+
+// StartPurger 猶予期間が過ぎたアカウントと、利用されていないゲストの削除を定期的に実行する（ctxが終了すると停止する）
+func (s *AccountService) StartPurger(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.config.PurgeInterval)
+		defer ticker.Stop()
+		for {
+			n, err := s.PurgeExpired(ctx)
+			if err != nil {
+				log.Printf("PurgeExpired Error: %v", err)
+			} else if n > 0 {
+				log.Printf("Purged %d deleted accounts", n)
+			}
+			n, err = s.PurgeStaleGuests(ctx)
+			if err != nil {
+				log.Printf("PurgeStaleGuests Error: %v", err)
+			} else if n > 0 {
+				log.Printf("Purged %d stale guest accounts", n)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Export ユーザーのデータをJSONとしてwに書き出す
+// 台帳とラン履歴はページごとに読み込みながら書き出すため、件数が多くてもメモリに載せきらない
+// ユーザーが存在しない場合は何も書き出さずにErrUserNotFoundを返す
+func (s *AccountService) Export(ctx context.Context, userID string, w io.Writer) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	ew := &exportWriter{w: w, enc: json.NewEncoder(w)}
+	ew.raw("{")
+	ew.field("exportedAt", time.Now())
+	ew.field("user", user)
+	ew.fieldFunc("settings", func() (any, error) { return s.settingsRepo.GetByUserID(ctx, userID) })
+	ew.fieldFunc("settingsProfiles", func() (any, error) { return s.profileRepo.FindByUserID(ctx, userID) })
+	ew.fieldFunc("items", func() (any, error) { return s.itemRepo.FindByUserID(ctx, userID) })
+	ew.fieldFunc("achievements", func() (any, error) { return s.achievementRepo.FindUnlockedByUserID(ctx, userID) })
+	ew.fieldFunc("unlocks", func() (any, error) { return s.unlockableRepo.FindUnlockedByUserID(ctx, userID) })
+	ew.fieldFunc("loadouts", func() (any, error) { return s.loadoutRepo.FindByUserID(ctx, userID) })
+
+	ew.array("coinTransactions", func(emit func(any)) error {
+		var beforeID int64
+		for {
+			txns, err := s.coinTxRepo.FindByUserID(ctx, userID, beforeID, exportPageSize)
+			if err != nil {
+				return err
+			}
+			for _, txn := range txns {
+				emit(txn)
+			}
+			if len(txns) < exportPageSize {
+				return nil
+			}
+			beforeID = txns[len(txns)-1].ID
+		}
+	})
+
+	ew.array("runs", func(emit func(any)) error {
+		filter := repository.RunFilter{UserID: userID, Limit: exportPageSize}
+		for {
+			runs, err := s.runRepo.FindCompleted(ctx, filter)
+			if err != nil {
+				return err
+			}
+			for _, run := range runs {
+				emit(run)
+			}
+			if len(runs) < exportPageSize {
+				return nil
+			}
+			filter.BeforeID = runs[len(runs)-1].ID
+		}
+	})
+
+	ew.raw("}\n")
+	return ew.err
+}
+
+// exportWriter JSONオブジェクトのフィールドを順に書き出す（最初のエラー以降は何もしない）
+type exportWriter struct {
+	w      io.Writer
+	enc    *json.Encoder
+	fields int
+	err    error
+}
+
+func (ew *exportWriter) raw(s string) {
+	if ew.err != nil {
+		return
+	}
+	_, ew.err = io.WriteString(ew.w, s)
+}
+
+func (ew *exportWriter) key(name string) {
+	if ew.fields > 0 {
+		ew.raw(",")
+	}
+	ew.fields++
+	ew.value(name)
+	ew.raw(":")
+}
+
+func (ew *exportWriter) value(v any) {
+	if ew.err != nil {
+		return
+	}
+	ew.err = ew.enc.Encode(v)
+}
+
+func (ew *exportWriter) field(name string, v any) {
+	ew.key(name)
+	ew.value(v)
+}
+
+func (ew *exportWriter) fieldFunc(name string, load func() (any, error)) {
+	if ew.err != nil {
+		return
+	}
+	v, err := load()
+	if err != nil {
+		ew.err = err
+		return
+	}
+	ew.field(name, v)
+}
+
+func (ew *exportWriter) array(name string, each func(emit func(any)) error) {
+	ew.key(name)
+	ew.raw("[")
+	n := 0
+	err := each(func(v any) {
+		if n > 0 {
+			ew.raw(",")
+		}
+		n++
+		ew.value(v)
+	})
+	if ew.err == nil {
+		ew.err = err
+	}
+	ew.raw("]")
+}
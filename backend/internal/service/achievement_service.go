@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/repository"
+	"github.com/uptrace/bun"
+)
+
+// achievementRule 実績の達成条件に対する現在の進捗を計算する
+type achievementRule func(ctx context.Context, ev *achievementEvaluator, a *entity.Achievement) (int, error)
+
+var achievementRules = map[string]achievementRule{
+	entity.AchievementRuleTotalClears: func(ctx context.Context, ev *achievementEvaluator, _ *entity.Achievement) (int, error) {
+		stats, err := ev.stats(ctx)
+		if err != nil {
+			return 0, err
+		}
+		return stats.Clears, nil
+	},
+	entity.AchievementRuleTotalKills: func(ctx context.Context, ev *achievementEvaluator, _ *entity.Achievement) (int, error) {
+		stats, err := ev.stats(ctx)
+		if err != nil {
+			return 0, err
+		}
+		return stats.TotalKills, nil
+	},
+	entity.AchievementRuleTotalRuns: func(ctx context.Context, ev *achievementEvaluator, _ *entity.Achievement) (int, error) {
+		stats, err := ev.stats(ctx)
+		if err != nil {
+			return 0, err
+		}
+		return stats.TotalRuns, nil
+	},
+	entity.AchievementRuleKillsInRun: func(ctx context.Context, ev *achievementEvaluator, _ *entity.Achievement) (int, error) {
+		stats, err := ev.stats(ctx)
+		if err != nil {
+			return 0, err
+		}
+		return stats.BestKillCount, nil
+	},
+	entity.AchievementRuleReachLevel: func(ctx context.Context, ev *achievementEvaluator, _ *entity.Achievement) (int, error) {
+		stats, err := ev.stats(ctx)
+		if err != nil {
+			return 0, err
+		}
+		return stats.BestLevel, nil
+	},
+	entity.AchievementRuleSurvivalTime: func(ctx context.Context, ev *achievementEvaluator, _ *entity.Achievement) (int, error) {
+		stats, err := ev.stats(ctx)
+		if err != nil {
+			return 0, err
+		}
+		return int(math.Floor(stats.BestSurvivalTime)), nil
+	},
+	entity.AchievementRuleClearWithOnlyWeapon: func(ctx context.Context, ev *achievementEvaluator, a *entity.Achievement) (int, error) {
+		return ev.runRepo.CountClearsWithOnlyWeapon(ctx, ev.userID, a.Params.Weapon)
+	},
+}
+
+// achievementEvaluator 1ユーザー分の実績判定に使うデータを保持する（通算成績は一度だけ集計する）
+type achievementEvaluator struct {
+	userID      string
+	runRepo     *repository.RunRepository
+	careerStats *entity.CareerStats
+}
+
+func (ev *achievementEvaluator) stats(ctx context.Context) (*entity.CareerStats, error) {
+	if ev.careerStats == nil {
+		stats, err := ev.runRepo.AggregateStats(ctx, ev.userID)
+		if err != nil {
+			return nil, err
+		}
+		ev.careerStats = stats
+	}
+	return ev.careerStats, nil
+}
+
+// progress 実績の進捗を計算する（未知の条件の場合は0）
+func (ev *achievementEvaluator) progress(ctx context.Context, a *entity.Achievement) (int, error) {
+	rule, ok := achievementRules[a.RuleType]
+	if !ok {
+		log.Printf("unknown achievement rule type %q (code=%s)", a.RuleType, a.Code)
+		return 0, nil
+	}
+	return rule(ctx, ev, a)
+}
+
+type AchievementService struct {
+	repo        *repository.AchievementRepository
+	runRepo     *repository.RunRepository
+	userService *UserService
+}
+
+func NewAchievementService(repo *repository.AchievementRepository, runRepo *repository.RunRepository, userService *UserService) *AchievementService {
+	return &AchievementService{repo: repo, runRepo: runRepo, userService: userService}
+}
+
+// AchievementProgress 実績と、その達成状況
+type AchievementProgress struct {
+	entity.Achievement
+	Progress   int        `json:"progress"`
+	Unlocked   bool       `json:"unlocked"`
+	UnlockedAt *time.Time `json:"unlockedAt"`
+}
+
+// GetAchievements 実績の一覧と、ユーザーの達成状況を取得する
+func (s *AchievementService) GetAchievements(ctx context.Context, userID string) ([]AchievementProgress, error) {
+	achievements, err := s.repo.FindActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	unlocked, err := s.unlockedMap(ctx, s.repo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ev := &achievementEvaluator{userID: userID, runRepo: s.runRepo}
+	result := make([]AchievementProgress, 0, len(achievements))
+	for i := range achievements {
+		a := achievements[i]
+		p := AchievementProgress{Achievement: a}
+		if ua, ok := unlocked[a.ID]; ok {
+			p.Unlocked = true
+			p.UnlockedAt = &ua.UnlockedAt
+			p.Progress = a.Target
+		} else {
+			progress, err := ev.progress(ctx, &a)
+			if err != nil {
+				return nil, err
+			}
+			p.Progress = min(progress, a.Target)
+		}
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+// EvaluateRunTx ランの終了時に未解除の実績を判定し、達成したものを解除して報酬を付与する
+// 新たに解除した実績を返す
+func (s *AchievementService) EvaluateRunTx(ctx context.Context, tx bun.Tx, userID, runID string) ([]entity.Achievement, error) {
+	repo := s.repo.WithTx(tx)
+	achievements, err := repo.FindActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	unlocked, err := s.unlockedMap(ctx, repo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ev := &achievementEvaluator{userID: userID, runRepo: s.runRepo.WithTx(tx)}
+	newlyUnlocked := []entity.Achievement{}
+	for i := range achievements {
+		a := achievements[i]
+		if _, ok := unlocked[a.ID]; ok {
+			continue
+		}
+		progress, err := ev.progress(ctx, &a)
+		if err != nil {
+			return nil, err
+		}
+		if progress < a.Target {
+			continue
+		}
+
+		ok, err := repo.Unlock(ctx, &entity.UserAchievement{
+			UserID:        userID,
+			AchievementID: a.ID,
+			RunID:         runID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		if a.RewardCoin > 0 {
+			_, err := s.userService.ApplyCoinChangeTx(ctx, tx, userID, CoinChange{
+				Delta:       a.RewardCoin,
+				Reason:      entity.CoinReasonAchievementReward,
+				ReferenceID: a.Code,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		newlyUnlocked = append(newlyUnlocked, a)
+	}
+	return newlyUnlocked, nil
+}
+
+func (s *AchievementService) unlockedMap(ctx context.Context, repo *repository.AchievementRepository, userID string) (map[int]entity.UserAchievement, error) {
+	unlocked, err := repo.FindUnlockedByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[int]entity.UserAchievement, len(unlocked))
+	for _, ua := range unlocked {
+		m[ua.AchievementID] = ua
+	}
+	return m, nil
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envInt 環境変数を整数として読み込む（未設定・不正な場合はデフォルト値）
+func envInt(name string, def int) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Printf("invalid %s=%q, using default %d", name, v, def)
+		return def
+	}
+	return n
+}
+
+// envFloat 環境変数を小数として読み込む（未設定・不正な場合はデフォルト値）
+func envFloat(name string, def float64) float64 {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		log.Printf("invalid %s=%q, using default %v", name, v, def)
+		return def
+	}
+	return f
+}
+
+// envLocation 環境変数をタイムゾーン名として読み込む（未設定・不正な場合はデフォルト値）
+func envLocation(name string, def string) *time.Location {
+	v := os.Getenv(name)
+	if v == "" {
+		v = def
+	}
+	loc, err := time.LoadLocation(v)
+	if err != nil {
+		log.Printf("invalid %s=%q, using UTC", name, v)
+		return time.UTC
+	}
+	return loc
+}
+
+// envDuration 環境変数を時間として読み込む（例: "30s", "24h"。未設定・不正な場合はデフォルト値）
+func envDuration(name string, def time.Duration) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Printf("invalid %s=%q, using default %s", name, v, def)
+		return def
+	}
+	return d
+}
+
+// envBool 環境変数を真偽値として読み込む（未設定・不正な場合はデフォルト値）
+func envBool(name string, def bool) bool {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Printf("invalid %s=%q, using default %t", name, v, def)
+		return def
+	}
+	return b
+}
+
+// envList 環境変数をカンマ区切りのリストとして読み込む（空の要素は除く）
+func envList(name string) []string {
+	var list []string
+	for _, v := range strings.Split(os.Getenv(name), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
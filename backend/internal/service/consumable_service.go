@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/repository"
+	"github.com/uptrace/bun"
+)
+
+var (
+	ErrItemNotConsumable = errors.New("item is not consumable")
+	ErrInsufficientItems = errors.New("insufficient items")
+)
+
+type ConsumableService struct {
+	repo     *repository.RunConsumableRepository
+	itemRepo *repository.ItemRepository
+	shopRepo *repository.ShopRepository
+}
+
+func NewConsumableService(repo *repository.RunConsumableRepository, itemRepo *repository.ItemRepository, shopRepo *repository.ShopRepository) *ConsumableService {
+	return &ConsumableService{repo: repo, itemRepo: itemRepo, shopRepo: shopRepo}
+}
+
+// ConsumeForRunTx ランに持ち込むアイテムを所持数から減算し、消費記録を作成する
+// 所持数の確認と減算は1つのUPDATEで行うため、同時に開始したランで二重に消費することはない
+func (s *ConsumableService) ConsumeForRunTx(ctx context.Context, tx bun.Tx, userID, runID string, itemIDs []int) ([]entity.RunConsumable, error) {
+	counts := map[int]int{}
+	for _, id := range itemIDs {
+		counts[id]++
+	}
+	// ロックの取得順を揃えるため、アイテムIDの昇順に処理する
+	ids := make([]int, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	shopRepo := s.shopRepo.WithTx(tx)
+	itemRepo := s.itemRepo.WithTx(tx)
+	consumables := make([]entity.RunConsumable, 0, len(ids))
+	for _, id := range ids {
+		shop, err := shopRepo.FindByIDIncludingInactive(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if shop == nil || !shop.IsConsumable() {
+			return nil, ErrItemNotConsumable
+		}
+
+		ok, err := itemRepo.Consume(ctx, userID, id, counts[id])
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrInsufficientItems
+		}
+
+		consumables = append(consumables, entity.RunConsumable{
+			RunID:    runID,
+			ItemID:   id,
+			Effect:   shop.Effect,
+			Quantity: counts[id],
+		})
+	}
+
+	if err := s.repo.WithTx(tx).Create(ctx, consumables); err != nil {
+		return nil, err
+	}
+	return consumables, nil
+}
+
+// FindForRunTx ランで消費したアイテムを取得する
+func (s *ConsumableService) FindForRunTx(ctx context.Context, tx bun.Tx, runID string) ([]entity.RunConsumable, error) {
+	return s.repo.WithTx(tx).FindByRunID(ctx, runID)
+}
+
+// RefundRunTx ランで消費したアイテムを所持数に戻し、返却済みにする
+func (s *ConsumableService) RefundRunTx(ctx context.Context, tx bun.Tx, userID, runID string) ([]entity.RunConsumable, error) {
+	repo := s.repo.WithTx(tx)
+	consumables, err := repo.FindByRunID(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	itemRepo := s.itemRepo.WithTx(tx)
+	for i := range consumables {
+		c := &consumables[i]
+		if c.RefundedAt != nil {
+			continue
+		}
+		item := &entity.Item{
+			UserID:   userID,
+			ItemID:   c.ItemID,
+			Quantity: c.Quantity,
+		}
+		if err := itemRepo.AddQuantity(ctx, item); err != nil {
+			return nil, err
+		}
+		c.RefundedAt = &now
+	}
+
+	if err := repo.MarkRefunded(ctx, runID, now); err != nil {
+		return nil, err
+	}
+	return consumables, nil
+}
+
+// hasConsumableEffect 消費したアイテムに指定した効果が含まれるかどうか
+func hasConsumableEffect(consumables []entity.RunConsumable, effect string) bool {
+	return slices.ContainsFunc(consumables, func(c entity.RunConsumable) bool { return c.Effect == effect })
+}
@@ -0,0 +1,56 @@
+package service
+
+import "time"
+
+// DefaultGameTimezone デイリー・ウィークリーの区切りに使うデフォルトのタイムゾーン
+const DefaultGameTimezone = "Asia/Tokyo"
+
+// GameCalendar デイリー・ウィークリーの期間を計算する
+// 日付は GAME_TIMEZONE の0時で切り替わり、週は月曜日始まりとする
+type GameCalendar struct {
+	loc *time.Location
+}
+
+func NewGameCalendar(loc *time.Location) *GameCalendar {
+	return &GameCalendar{loc: loc}
+}
+
+// LoadGameCalendar 環境変数 GAME_TIMEZONE からカレンダーを作成する
+func LoadGameCalendar() *GameCalendar {
+	return NewGameCalendar(envLocation("GAME_TIMEZONE", DefaultGameTimezone))
+}
+
+// Location 期間の区切りに使うタイムゾーン
+func (c *GameCalendar) Location() *time.Location {
+	return c.loc
+}
+
+// ParseDate "2006-01-02" 形式の日付を GAME_TIMEZONE での0時として解釈する
+func (c *GameCalendar) ParseDate(s string) (time.Time, error) {
+	return time.ParseInLocation(time.DateOnly, s, c.loc)
+}
+
+// DayStart tを含む日の開始日（UTCの0時で表した日付）
+func (c *GameCalendar) DayStart(t time.Time) time.Time {
+	local := t.In(c.loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// WeekStart tを含む週の開始日（月曜日。UTCの0時で表した日付）
+func (c *GameCalendar) WeekStart(t time.Time) time.Time {
+	day := c.DayStart(t)
+	offset := (int(day.Weekday()) + 6) % 7 // 月曜日を0とする
+	return day.AddDate(0, 0, -offset)
+}
+
+// NextDayStart tを含む日の翌日の開始時刻（GAME_TIMEZONEでの0時）
+func (c *GameCalendar) NextDayStart(t time.Time) time.Time {
+	day := c.DayStart(t)
+	return time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, c.loc)
+}
+
+// NextWeekStart tを含む週の翌週の開始時刻（GAME_TIMEZONEでの月曜日0時）
+func (c *GameCalendar) NextWeekStart(t time.Time) time.Time {
+	week := c.WeekStart(t)
+	return time.Date(week.Year(), week.Month(), week.Day()+7, 0, 0, 0, 0, c.loc)
+}
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/repository"
+	"github.com/uptrace/bun"
+)
+
+var (
+	ErrGuestNotFound = errors.New("guest account not found")
+	ErrMergeToGuest  = errors.New("cannot merge into a guest account")
+)
+
+type GuestService struct {
+	userRepo        *repository.UserRepository
+	settingsRepo    *repository.SettingsRepository
+	profileRepo     *repository.SettingsProfileRepository
+	itemRepo        *repository.ItemRepository
+	runRepo         *repository.RunRepository
+	runReviewRepo   *repository.RunReviewRepository
+	leaderboardRepo *repository.LeaderboardRepository
+	achievementRepo *repository.AchievementRepository
+	unlockableRepo  *repository.UnlockableRepository
+	coinTxRepo      *repository.CoinTransactionRepository
+	userService     *UserService
+	txManager       *repository.TxManager
+}
+
+func NewGuestService(userRepo *repository.UserRepository, settingsRepo *repository.SettingsRepository, profileRepo *repository.SettingsProfileRepository, itemRepo *repository.ItemRepository, runRepo *repository.RunRepository, runReviewRepo *repository.RunReviewRepository, leaderboardRepo *repository.LeaderboardRepository, achievementRepo *repository.AchievementRepository, unlockableRepo *repository.UnlockableRepository, coinTxRepo *repository.CoinTransactionRepository, userService *UserService, txManager *repository.TxManager) *GuestService {
+	return &GuestService{
+		userRepo:        userRepo,
+		settingsRepo:    settingsRepo,
+		profileRepo:     profileRepo,
+		itemRepo:        itemRepo,
+		runRepo:         runRepo,
+		runReviewRepo:   runReviewRepo,
+		leaderboardRepo: leaderboardRepo,
+		achievementRepo: achievementRepo,
+		unlockableRepo:  unlockableRepo,
+		coinTxRepo:      coinTxRepo,
+		userService:     userService,
+		txManager:       txManager,
+	}
+}
+
+// MergeResult ゲストアカウントから引き継いだデータの件数
+type MergeResult struct {
+	User             *entity.User `json:"user"`
+	Coins            int          `json:"coins"`
+	CoinTransactions int          `json:"coinTransactions"` // 複製したゲストの台帳の件数
+	Items            int          `json:"items"`
+	Runs             int          `json:"runs"`
+	Achievements     int          `json:"achievements"`
+	Unlocks          int          `json:"unlocks"`
+	Settings         bool         `json:"settings"`
+	SettingsProfiles int          `json:"settingsProfiles"` // 引き継いだ端末ごとの設定の件数
+}
+
+// CreateGuest ゲストユーザーを作成する
+func (s *GuestService) CreateGuest(ctx context.Context) (*entity.User, error) {
+	id, err := newUUID()
+	if err != nil {
+		return nil, err
+	}
+	user := &entity.User{
+		ID:      id,
+		Name:    "Guest-" + id[:8],
+		IsGuest: true,
+	}
+	if err := s.userRepo.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return s.userRepo.FindByID(ctx, id)
+}
+
+// TouchGuest ゲストの最終利用日時を更新する（トークンを再発行する際に呼び出す）
+func (s *GuestService) TouchGuest(ctx context.Context, guestID string) error {
+	ok, err := s.userRepo.TouchGuest(ctx, guestID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrGuestNotFound
+	}
+	return nil
+}
+
+// MergeGuest ゲストアカウントのコイン・アイテム・設定・ラン履歴などをユーザーに引き継ぎ、ゲストアカウントを削除する
+// ミッションの進捗と装備プリセットは引き継がない
+func (s *GuestService) MergeGuest(ctx context.Context, userID, guestID string) (*MergeResult, error) {
+	result := &MergeResult{}
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		userRepo := s.userRepo.WithTx(tx)
+
+		// 同時に引き継ぎが行われても二重に反映されないよう、両方のユーザー行をID順にロックする
+		var user, guest *entity.User
+		for _, id := range sortedPair(userID, guestID) {
+			u, err := userRepo.FindByIDForUpdate(ctx, id)
+			if err != nil {
+				return err
+			}
+			if id == userID {
+				user = u
+			} else {
+				guest = u
+			}
+		}
+		if user == nil {
+			return ErrUserNotFound
+		}
+		if user.IsGuest {
+			return ErrMergeToGuest
+		}
+		if guest == nil || !guest.IsGuest {
+			return ErrGuestNotFound
+		}
+
+		// ゲストの削除で台帳がカスケード削除されないよう、引き継ぎの記録より前に複製しておく
+		copied, err := s.coinTxRepo.WithTx(tx).CopyToUser(ctx, guestID, userID)
+		if err != nil {
+			return err
+		}
+		result.CoinTransactions = copied
+
+		if guest.Coin > 0 {
+			_, err := s.userService.ApplyCoinChangeTx(ctx, tx, userID, CoinChange{
+				Delta:       guest.Coin,
+				Reason:      entity.CoinReasonGuestMerge,
+				ReferenceID: guestID,
+			})
+			if err != nil {
+				return err
+			}
+			result.Coins = guest.Coin
+		}
+
+		items, err := s.mergeItems(ctx, tx, userID, guestID)
+		if err != nil {
+			return err
+		}
+		result.Items = items
+
+		merged, err := s.mergeSettings(ctx, tx, userID, guestID)
+		if err != nil {
+			return err
+		}
+		result.Settings = merged
+
+		profiles, err := s.mergeSettingsProfiles(ctx, tx, userID, guestID)
+		if err != nil {
+			return err
+		}
+		result.SettingsProfiles = profiles
+
+		// ランの付け替え後に、ゲストの自己ベストをランキングに取り込む
+		runs, err := s.runRepo.WithTx(tx).ReassignUser(ctx, guestID, userID)
+		if err != nil {
+			return err
+		}
+		result.Runs = runs
+		if err := s.runReviewRepo.WithTx(tx).ReassignUser(ctx, guestID, userID); err != nil {
+			return err
+		}
+		if err := s.leaderboardRepo.WithTx(tx).MergeUser(ctx, guestID, userID); err != nil {
+			return err
+		}
+
+		achievementRepo := s.achievementRepo.WithTx(tx)
+		achievements, err := achievementRepo.FindUnlockedByUserID(ctx, guestID)
+		if err != nil {
+			return err
+		}
+		for _, ua := range achievements {
+			ua.UserID = userID
+			ok, err := achievementRepo.Unlock(ctx, &ua)
+			if err != nil {
+				return err
+			}
+			if ok {
+				result.Achievements++
+			}
+		}
+
+		unlockableRepo := s.unlockableRepo.WithTx(tx)
+		unlocks, err := unlockableRepo.FindUnlockedByUserID(ctx, guestID)
+		if err != nil {
+			return err
+		}
+		for _, uu := range unlocks {
+			uu.UserID = userID
+			ok, err := unlockableRepo.Unlock(ctx, &uu)
+			if err != nil {
+				return err
+			}
+			if ok {
+				result.Unlocks++
+			}
+		}
+
+		// 引き継がなかったデータはカスケード削除される
+		return userRepo.Delete(ctx, guestID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	result.User = user
+	return result, nil
+}
+
+// mergeItems 所持アイテムを引き継ぎ、件数を返す
+// 恒久強化はレベルの高い方、それ以外は所持数の合計とする
+func (s *GuestService) mergeItems(ctx context.Context, tx bun.Tx, userID, guestID string) (int, error) {
+	itemRepo := s.itemRepo.WithTx(tx)
+	items, err := itemRepo.FindByUserID(ctx, guestID)
+	if err != nil {
+		return 0, err
+	}
+
+	merged := 0
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			continue
+		}
+		target := &entity.Item{UserID: userID, ItemID: item.ItemID, Quantity: item.Quantity}
+		if item.Shop != nil && item.Shop.IsUpgrade() {
+			owned, err := itemRepo.FindByUserAndItemID(ctx, userID, item.ItemID)
+			if err != nil {
+				return 0, err
+			}
+			if owned != nil && owned.Quantity >= item.Quantity {
+				continue
+			}
+			if err := itemRepo.Upsert(ctx, target); err != nil {
+				return 0, err
+			}
+		} else if err := itemRepo.AddQuantity(ctx, target); err != nil {
+			return 0, err
+		}
+		merged++
+	}
+	return merged, nil
+}
+
+// mergeSettings ユーザーに設定がないか、ゲストの方が新しく更新されている場合にゲストの設定を引き継ぐ
+func (s *GuestService) mergeSettings(ctx context.Context, tx bun.Tx, userID, guestID string) (bool, error) {
+	settingsRepo := s.settingsRepo.WithTx(tx)
+	guest, err := settingsRepo.GetByUserID(ctx, guestID)
+	if err != nil || guest == nil {
+		return false, err
+	}
+	current, err := settingsRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if current != nil && !guest.UpdatedAt.After(current.UpdatedAt) {
+		return false, nil
+	}
+
+	guest.UserID = userID
+	guest.CreatedAt, guest.UpdatedAt = time.Time{}, time.Time{}
+	if err := settingsRepo.Upsert(ctx, guest); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// mergeSettingsProfiles ゲストの端末ごとの設定を引き継ぐ
+// 同じ端末の設定がある場合はゲストの方が新しく更新されているときだけ上書きし、
+// 新しい端末の設定は上限に達するまで古いものから順に追加する
+func (s *GuestService) mergeSettingsProfiles(ctx context.Context, tx bun.Tx, userID, guestID string) (int, error) {
+	profileRepo := s.profileRepo.WithTx(tx)
+	guestProfiles, err := profileRepo.FindByUserID(ctx, guestID)
+	if err != nil || len(guestProfiles) == 0 {
+		return 0, err
+	}
+	count, err := profileRepo.CountByUserID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	merged := 0
+	for _, profile := range guestProfiles {
+		existing, err := profileRepo.FindByDeviceID(ctx, userID, profile.DeviceID)
+		if err != nil {
+			return 0, err
+		}
+		if existing != nil && !profile.UpdatedAt.After(existing.UpdatedAt) {
+			continue
+		}
+		if existing == nil && count >= maxSettingsProfiles {
+			continue
+		}
+
+		target := &entity.SettingsProfile{
+			UserID:    userID,
+			DeviceID:  profile.DeviceID,
+			Name:      profile.Name,
+			Overrides: profile.Overrides,
+		}
+		if err := profileRepo.Upsert(ctx, target); err != nil {
+			return 0, err
+		}
+		if existing == nil {
+			count++
+		}
+		merged++
+	}
+	return merged, nil
+}
+
+// sortedPair 2つのIDを昇順に並べて返す
+func sortedPair(a, b string) [2]string {
+	if a > b {
+		return [2]string{b, a}
+	}
+	return [2]string{a, b}
+}
+
+// newUUID ランダムなUUID (v4) を生成する
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/repository"
+	"github.com/uptrace/bun"
+)
+
+// leaderboardNeighborCount 自分の順位の前後に表示する人数
+const leaderboardNeighborCount = 2
+
+var (
+	ErrUnknownLeaderboard = errors.New("unknown leaderboard")
+	ErrUnknownPeriod      = errors.New("unknown leaderboard period")
+)
+
+// leaderboardScore ランからボードのスコアを算出する
+// 対象外のランの場合はfalseを返す。rankValueは大きいほど上位になる値
+type leaderboardScore func(run *entity.Run) (score, rankValue float64, ok bool)
+
+// leaderboardScores ボードごとのスコアの算出方法
+// 変更する場合はLeaderboardRepository.RebuildPeriodsの集計も合わせて変更する
+var leaderboardScores = map[string]leaderboardScore{
+	entity.LeaderboardSurvival: func(run *entity.Run) (float64, float64, bool) {
+		return run.SurvivalTime, run.SurvivalTime, true
+	},
+	entity.LeaderboardKills: func(run *entity.Run) (float64, float64, bool) {
+		return float64(run.KillCount), float64(run.KillCount), true
+	},
+	entity.LeaderboardFastestClear: func(run *entity.Run) (float64, float64, bool) {
+		if !run.Cleared || run.FinishedAt == nil {
+			return 0, 0, false
+		}
+		// 生存時間はクリア時点で一定のため、ラン開始からクリアまでの実時間で競う
+		d := run.FinishedAt.Sub(run.StartedAt).Seconds()
+		return d, -d, true
+	},
+}
+
+type LeaderboardService struct {
+	repo      *repository.LeaderboardRepository
+	calendar  *GameCalendar
+	txManager *repository.TxManager
+}
+
+func NewLeaderboardService(repo *repository.LeaderboardRepository, calendar *GameCalendar, txManager *repository.TxManager) *LeaderboardService {
+	return &LeaderboardService{repo: repo, calendar: calendar, txManager: txManager}
+}
+
+// LeaderboardRow ランキングの1行
+type LeaderboardRow struct {
+	Rank       int       `json:"rank"`
+	UserID     string    `json:"userId"`
+	Name       string    `json:"name"`
+	Avatar     string    `json:"avatar,omitempty"` // ゲーム内のアバターを選んでいる場合のみ
+	AvatarURL  string    `json:"avatarUrl"`
+	Score      float64   `json:"score"`
+	RunID      string    `json:"runId"`
+	AchievedAt time.Time `json:"achievedAt"`
+}
+
+// Leaderboard ランキングの表示内容
+type Leaderboard struct {
+	Board       string           `json:"board"`
+	Period      string           `json:"period"`
+	PeriodStart string           `json:"periodStart"`
+	Entries     []LeaderboardRow `json:"entries"`
+	Me          *LeaderboardRow  `json:"me"`
+	Neighbors   []LeaderboardRow `json:"neighbors"`
+}
+
+// RecordRunTx 終了したランの結果を各ボード・期間の自己ベストとして反映する
+func (s *LeaderboardService) RecordRunTx(ctx context.Context, tx bun.Tx, run *entity.Run) error {
+	if run.Status != entity.RunStatusFinished || run.FinishedAt == nil {
+		return nil
+	}
+
+	repo := s.repo.WithTx(tx)
+	for board, scoreFn := range leaderboardScores {
+		score, rankValue, ok := scoreFn(run)
+		if !ok {
+			continue
+		}
+		for _, period := range []string{entity.LeaderboardPeriodAll, entity.LeaderboardPeriodDaily, entity.LeaderboardPeriodWeekly} {
+			entry := &entity.LeaderboardEntry{
+				Board:       board,
+				Period:      period,
+				PeriodStart: s.periodStart(period, *run.FinishedAt),
+				UserID:      run.UserID,
+				RunID:       run.ID,
+				Score:       score,
+				RankValue:   rankValue,
+				AchievedAt:  *run.FinishedAt,
+			}
+			if err := repo.UpsertBest(ctx, entry); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SyncTimezone 日別・週別のエントリーを集計したタイムゾーンがGAME_TIMEZONEと異なる場合に、ランから集計し直す
+// 集計し直した場合はtrueを返す
+func (s *LeaderboardService) SyncTimezone(ctx context.Context) (bool, error) {
+	timezone := s.calendar.Location().String()
+	rebuilt := false
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		// 複数のサーバーが同時に起動しても集計し直すのは一度だけになるようロックする
+		repo := s.repo.WithTx(tx)
+		current, err := repo.LockTimezone(ctx)
+		if err != nil {
+			return err
+		}
+		if current == timezone {
+			return nil
+		}
+		rebuilt = true
+		return repo.RebuildPeriods(ctx, timezone)
+	})
+	if err != nil {
+		return false, err
+	}
+	return rebuilt, nil
+}
+
+// GetLeaderboard 現在の期間のランキング上位と、呼び出したユーザーの順位・前後のユーザーを取得する
+func (s *LeaderboardService) GetLeaderboard(ctx context.Context, userID, board, period string, limit int) (*Leaderboard, error) {
+	if _, ok := leaderboardScores[board]; !ok {
+		return nil, ErrUnknownLeaderboard
+	}
+	switch period {
+	case entity.LeaderboardPeriodAll, entity.LeaderboardPeriodDaily, entity.LeaderboardPeriodWeekly:
+	default:
+		return nil, ErrUnknownPeriod
+	}
+	start := s.periodStart(period, time.Now())
+
+	top, err := s.repo.FindTop(ctx, board, period, start, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	lb := &Leaderboard{
+		Board:       board,
+		Period:      period,
+		PeriodStart: start.Format(time.DateOnly),
+		Entries:     make([]LeaderboardRow, 0, len(top)),
+		Neighbors:   []LeaderboardRow{},
+	}
+
+	// 上位リストは先頭から順に並んでいるため、同点を考慮した順位をその場で計算できる
+	for i := range top {
+		rank := i + 1
+		if i > 0 && top[i].RankValue == top[i-1].RankValue {
+			rank = lb.Entries[i-1].Rank
+		}
+		lb.Entries = append(lb.Entries, toLeaderboardRow(&top[i], rank))
+	}
+
+	me, err := s.repo.FindByUserID(ctx, board, period, start, userID)
+	if err != nil {
+		return nil, err
+	}
+	if me == nil {
+		return lb, nil
+	}
+	row, err := s.rankedRow(ctx, me)
+	if err != nil {
+		return nil, err
+	}
+	lb.Me = &row
+
+	above, err := s.repo.FindAbove(ctx, me, leaderboardNeighborCount)
+	if err != nil {
+		return nil, err
+	}
+	below, err := s.repo.FindBelow(ctx, me, leaderboardNeighborCount)
+	if err != nil {
+		return nil, err
+	}
+
+	// 上位側は近い順に取得しているため、逆順にして順位順に並べる
+	for i := len(above) - 1; i >= 0; i-- {
+		row, err := s.rankedRow(ctx, &above[i])
+		if err != nil {
+			return nil, err
+		}
+		lb.Neighbors = append(lb.Neighbors, row)
+	}
+	lb.Neighbors = append(lb.Neighbors, *lb.Me)
+	for i := range below {
+		row, err := s.rankedRow(ctx, &below[i])
+		if err != nil {
+			return nil, err
+		}
+		lb.Neighbors = append(lb.Neighbors, row)
+	}
+	return lb, nil
+}
+
+// rankedRow エントリーの順位を計算して表示用の行に変換する
+func (s *LeaderboardService) rankedRow(ctx context.Context, entry *entity.LeaderboardEntry) (LeaderboardRow, error) {
+	better, err := s.repo.CountBetter(ctx, entry.Board, entry.Period, entry.PeriodStart, entry.RankValue)
+	if err != nil {
+		return LeaderboardRow{}, err
+	}
+	return toLeaderboardRow(entry, better+1), nil
+}
+
+func (s *LeaderboardService) periodStart(period string, t time.Time) time.Time {
+	switch period {
+	case entity.LeaderboardPeriodDaily:
+		return s.calendar.DayStart(t)
+	case entity.LeaderboardPeriodWeekly:
+		return s.calendar.WeekStart(t)
+	default:
+		return time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+func toLeaderboardRow(entry *entity.LeaderboardEntry, rank int) LeaderboardRow {
+	row := LeaderboardRow{
+		Rank:       rank,
+		UserID:     entry.UserID,
+		Score:      entry.Score,
+		RunID:      entry.RunID,
+		AchievedAt: entry.AchievedAt,
+	}
+	if entry.User != nil {
+		row.Name = entry.User.PublicName()
+		row.Avatar = entry.User.Avatar
+		row.AvatarURL = entry.User.PublicAvatarURL()
+	}
+	return row
+}
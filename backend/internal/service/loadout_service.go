@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/repository"
+)
+
+const (
+	// maxLoadoutPresets ユーザーごとに保存できる装備プリセットの上限
+	maxLoadoutPresets = 10
+	// maxLoadoutConsumables 1回のランに持ち込めるアイテム数の上限
+	maxLoadoutConsumables = 3
+)
+
+var (
+	ErrLoadoutNotFound           = errors.New("loadout not found")
+	ErrLoadoutLimitReached       = errors.New("loadout preset limit reached")
+	ErrLoadoutWeaponUnavailable  = errors.New("weapon is not unlocked")
+	ErrLoadoutSpecialUnavailable = errors.New("special skill is not unlocked")
+	ErrLoadoutTooManyItems       = errors.New("too many consumables in loadout")
+	ErrLoadoutItemNotOwned       = errors.New("consumable is not owned")
+)
+
+// StartingBonuses ラン開始時にプレイヤーへ適用するステータス補正
+// 倍率は1.0を基準とした値、それ以外は基礎値への加算量
+type StartingBonuses struct {
+	AttackPower        int     `json:"attackPower"`
+	Defense            int     `json:"defense"`
+	SpeedMultiplier    float64 `json:"speedMultiplier"`
+	CooldownMultiplier float64 `json:"cooldownMultiplier"`
+	ProjectileCount    int     `json:"projectileCount"`
+	MagnetMultiplier   float64 `json:"magnetMultiplier"`
+	ExpMultiplier      float64 `json:"expMultiplier"`
+}
+
+// upgradeEffects 恒久強化1レベルあたりの効果（クライアントのPlayer.addSkillと一致させる）
+var upgradeEffects = map[string]func(b *StartingBonuses){
+	entity.SkillAttackUp:     func(b *StartingBonuses) { b.AttackPower += 2 },
+	entity.SkillDefenseUp:    func(b *StartingBonuses) { b.Defense += 10 },
+	entity.SkillSpeedUp:      func(b *StartingBonuses) { b.SpeedMultiplier += 0.1 },
+	entity.SkillCooldownDown: func(b *StartingBonuses) { b.CooldownMultiplier *= 0.9 },
+	entity.SkillMultiShot:    func(b *StartingBonuses) { b.ProjectileCount++ },
+	entity.SkillMagnetUp:     func(b *StartingBonuses) { b.MagnetMultiplier += 0.25 },
+	entity.SkillExpUp:        func(b *StartingBonuses) { b.ExpMultiplier += 0.1 },
+}
+
+// OwnedUpgrade 所持している恒久強化とそのレベル
+type OwnedUpgrade struct {
+	ItemID    int    `json:"itemId"`
+	SkillType string `json:"skillType"`
+	Level     int    `json:"level"`
+	MaxLevel  int    `json:"maxLevel"`
+}
+
+// Loadout ゲームクライアントがラン開始時に使う恒久強化の情報
+type Loadout struct {
+	Upgrades []OwnedUpgrade  `json:"upgrades"`
+	Bonuses  StartingBonuses `json:"bonuses"`
+}
+
+type LoadoutService struct {
+	repo          *repository.LoadoutRepository
+	itemRepo      *repository.ItemRepository
+	unlockService *UnlockService
+}
+
+func NewLoadoutService(repo *repository.LoadoutRepository, itemRepo *repository.ItemRepository, unlockService *UnlockService) *LoadoutService {
+	return &LoadoutService{repo: repo, itemRepo: itemRepo, unlockService: unlockService}
+}
+
+// GetLoadout 所持している恒久強化と、それらから計算した開始時のステータス補正を取得する
+func (s *LoadoutService) GetLoadout(ctx context.Context, userID string) (*Loadout, error) {
+	items, err := s.itemRepo.FindUpgradesByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	loadout := &Loadout{
+		Upgrades: make([]OwnedUpgrade, 0, len(items)),
+		Bonuses:  computeStartingBonuses(items),
+	}
+	for _, item := range items {
+		loadout.Upgrades = append(loadout.Upgrades, OwnedUpgrade{
+			ItemID:    item.ItemID,
+			SkillType: item.Shop.SkillType,
+			Level:     min(item.Quantity, item.Shop.MaxLevel),
+			MaxLevel:  item.Shop.MaxLevel,
+		})
+	}
+	return loadout, nil
+}
+
+// computeStartingBonuses 所持している恒久強化のレベル分だけ効果を重ねる
+func computeStartingBonuses(items []entity.Item) StartingBonuses {
+	b := StartingBonuses{
+		SpeedMultiplier:    1,
+		CooldownMultiplier: 1,
+		MagnetMultiplier:   1,
+		ExpMultiplier:      1,
+	}
+	for _, item := range items {
+		if item.Shop == nil {
+			continue
+		}
+		apply, ok := upgradeEffects[item.Shop.SkillType]
+		if !ok {
+			continue
+		}
+		for range min(item.Quantity, item.Shop.MaxLevel) {
+			apply(&b)
+		}
+	}
+	return b
+}
+
+// ListPresets ユーザーの装備プリセット一覧を取得する
+func (s *LoadoutService) ListPresets(ctx context.Context, userID string) ([]entity.Loadout, error) {
+	return s.repo.FindByUserID(ctx, userID)
+}
+
+// SavePreset 装備プリセットを検証して保存する（同じ名前のプリセットは上書きする）
+func (s *LoadoutService) SavePreset(ctx context.Context, loadout *entity.Loadout) (*entity.Loadout, error) {
+	if err := s.validatePreset(ctx, loadout); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.FindByName(ctx, loadout.UserID, loadout.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		count, err := s.repo.CountByUserID(ctx, loadout.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if count >= maxLoadoutPresets {
+			return nil, ErrLoadoutLimitReached
+		}
+	}
+
+	if err := s.repo.Upsert(ctx, loadout); err != nil {
+		return nil, err
+	}
+	return loadout, nil
+}
+
+// DeletePreset 装備プリセットを削除する
+func (s *LoadoutService) DeletePreset(ctx context.Context, userID, name string) error {
+	ok, err := s.repo.Delete(ctx, userID, name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLoadoutNotFound
+	}
+	return nil
+}
+
+// SnapshotPreset ラン開始時に、装備プリセットを再検証して恒久強化のレベルとともにスナップショットを作る
+func (s *LoadoutService) SnapshotPreset(ctx context.Context, userID, name string) (*entity.LoadoutSnapshot, error) {
+	loadout, err := s.repo.FindByName(ctx, userID, name)
+	if err != nil {
+		return nil, err
+	}
+	if loadout == nil {
+		return nil, ErrLoadoutNotFound
+	}
+	// 保存後にアイテムを使い切っている場合があるため、開始時点の所持状況で検証し直す
+	if err := s.validatePreset(ctx, loadout); err != nil {
+		return nil, err
+	}
+
+	upgrades, err := s.itemRepo.FindUpgradesByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &entity.LoadoutSnapshot{
+		Name:        loadout.Name,
+		Weapon:      loadout.Weapon,
+		SpecialType: loadout.SpecialType,
+		Consumables: loadout.Consumables,
+		Upgrades:    make(map[string]int, len(upgrades)),
+	}
+	for _, item := range upgrades {
+		snapshot.Upgrades[item.Shop.SkillType] = min(item.Quantity, item.Shop.MaxLevel)
+	}
+	return snapshot, nil
+}
+
+// validatePreset 武器・必殺技が解放済みで、持ち込むアイテムを所持していることを確認する
+func (s *LoadoutService) validatePreset(ctx context.Context, loadout *entity.Loadout) error {
+	pickable, err := s.unlockService.GetPickableSkills(ctx, loadout.UserID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(pickable.Weapons, loadout.Weapon) {
+		return ErrLoadoutWeaponUnavailable
+	}
+	if !slices.Contains(pickable.Specials, loadout.SpecialType) {
+		return ErrLoadoutSpecialUnavailable
+	}
+
+	if len(loadout.Consumables) > maxLoadoutConsumables {
+		return ErrLoadoutTooManyItems
+	}
+	if len(loadout.Consumables) == 0 {
+		return nil
+	}
+	items, err := s.itemRepo.FindByUserID(ctx, loadout.UserID)
+	if err != nil {
+		return err
+	}
+	// 同じアイテムを複数持ち込む場合は、その数だけ所持している必要がある
+	required := map[int]int{}
+	for _, itemID := range loadout.Consumables {
+		required[itemID]++
+	}
+	for itemID, n := range required {
+		i := slices.IndexFunc(items, func(item entity.Item) bool { return item.ItemID == itemID })
+		if i < 0 || items[i].Quantity < n {
+			return ErrLoadoutItemNotOwned
+		}
+		if items[i].Shop == nil || !items[i].Shop.IsConsumable() {
+			return ErrItemNotConsumable
+		}
+	}
+	return nil
+}
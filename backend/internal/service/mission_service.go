@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/repository"
+	"github.com/uptrace/bun"
+)
+
+var (
+	ErrMissionNotFound       = errors.New("mission not found")
+	ErrMissionNotCompleted   = errors.New("mission not completed")
+	ErrMissionAlreadyClaimed = errors.New("mission reward already claimed")
+	ErrMissionExpired        = errors.New("mission expired")
+)
+
+// MissionConfig 期間ごとに割り当てるミッション数と、期間の終了後も報酬を受け取れる期間
+type MissionConfig struct {
+	DailyCount       int
+	WeeklyCount      int
+	ClaimGracePeriod time.Duration // 期間の終了間際に達成したミッションの報酬を受け取り損ねないようにする
+}
+
+// LoadMissionConfig 環境変数から設定を読み込む（未設定の項目はデフォルト値）
+func LoadMissionConfig() MissionConfig {
+	return MissionConfig{
+		DailyCount:       envInt("MISSIONS_DAILY_COUNT", 3),
+		WeeklyCount:      envInt("MISSIONS_WEEKLY_COUNT", 2),
+		ClaimGracePeriod: envDuration("MISSIONS_CLAIM_GRACE_PERIOD", 24*time.Hour),
+	}
+}
+
+type MissionService struct {
+	repo        *repository.MissionRepository
+	userService *UserService
+	calendar    *GameCalendar
+	config      MissionConfig
+	txManager   *repository.TxManager
+}
+
+func NewMissionService(repo *repository.MissionRepository, userService *UserService, calendar *GameCalendar, config MissionConfig, txManager *repository.TxManager) *MissionService {
+	return &MissionService{repo: repo, userService: userService, calendar: calendar, config: config, txManager: txManager}
+}
+
+// MissionBoard 現在のデイリー・ウィークリーミッション
+type MissionBoard struct {
+	Daily          []entity.UserMission `json:"daily"`
+	Weekly         []entity.UserMission `json:"weekly"`
+	Unclaimed      []entity.UserMission `json:"unclaimed"` // 期間は終了したが、まだ報酬を受け取れる達成済みのミッション
+	DailyResetsAt  time.Time            `json:"dailyResetsAt"`
+	WeeklyResetsAt time.Time            `json:"weeklyResetsAt"`
+}
+
+// GetMissions 現在の期間のミッションを取得する（未割り当ての場合は割り当てる）
+func (s *MissionService) GetMissions(ctx context.Context, userID string) (*MissionBoard, error) {
+	now := time.Now()
+	board := &MissionBoard{
+		DailyResetsAt:  s.calendar.NextDayStart(now),
+		WeeklyResetsAt: s.calendar.NextWeekStart(now),
+	}
+
+	for _, period := range []string{entity.MissionPeriodDaily, entity.MissionPeriodWeekly} {
+		start := s.periodStart(period, now)
+		if err := s.ensureMissions(ctx, s.repo, userID, period, now); err != nil {
+			return nil, err
+		}
+		missions, err := s.repo.FindUserMissions(ctx, userID, period, start)
+		if err != nil {
+			return nil, err
+		}
+		if period == entity.MissionPeriodDaily {
+			board.Daily = missions
+		} else {
+			board.Weekly = missions
+		}
+	}
+
+	unclaimed, err := s.repo.FindUnclaimedExpired(ctx, userID, now.Add(-s.config.ClaimGracePeriod), now)
+	if err != nil {
+		return nil, err
+	}
+	board.Unclaimed = unclaimed
+	return board, nil
+}
+
+// ApplyRunTx 終了したランの結果を、ランが終了した期間のミッションの進捗に反映する
+func (s *MissionService) ApplyRunTx(ctx context.Context, tx bun.Tx, run *entity.Run) error {
+	if run.Status != entity.RunStatusFinished || run.FinishedAt == nil {
+		return nil
+	}
+
+	repo := s.repo.WithTx(tx)
+	for _, period := range []string{entity.MissionPeriodDaily, entity.MissionPeriodWeekly} {
+		if err := s.ensureMissions(ctx, repo, run.UserID, period, *run.FinishedAt); err != nil {
+			return err
+		}
+		missions, err := repo.FindUserMissionsForUpdate(ctx, run.UserID, period, s.periodStart(period, *run.FinishedAt))
+		if err != nil {
+			return err
+		}
+		for i := range missions {
+			m := &missions[i]
+			if m.CompletedAt != nil || m.Template == nil {
+				continue
+			}
+			progress := missionProgress(m.Template, m.Progress, run)
+			if progress == m.Progress {
+				continue
+			}
+			m.Progress = min(progress, m.Target)
+			if m.Progress >= m.Target {
+				completedAt := *run.FinishedAt
+				m.CompletedAt = &completedAt
+			}
+			if err := repo.UpdateProgress(ctx, m); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ClaimReward 達成したミッションの報酬を受け取る（1つのミッションにつき一度だけ）
+// 期間の終了後もClaimGracePeriodの間は受け取れる
+func (s *MissionService) ClaimReward(ctx context.Context, userID string, missionID int64) (*entity.UserMission, error) {
+	var claimed *entity.UserMission
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		repo := s.repo.WithTx(tx)
+		mission, err := repo.FindUserMissionForUpdate(ctx, missionID, userID)
+		if err != nil {
+			return err
+		}
+		if mission == nil {
+			return ErrMissionNotFound
+		}
+		if mission.ClaimedAt != nil {
+			return ErrMissionAlreadyClaimed
+		}
+		if mission.CompletedAt == nil {
+			return ErrMissionNotCompleted
+		}
+		now := time.Now()
+		if !now.Before(mission.ExpiresAt.Add(s.config.ClaimGracePeriod)) {
+			return ErrMissionExpired
+		}
+
+		mission.ClaimedAt = &now
+		if err := repo.MarkClaimed(ctx, mission); err != nil {
+			return err
+		}
+		if mission.RewardCoin > 0 {
+			_, err := s.userService.ApplyCoinChangeTx(ctx, tx, userID, CoinChange{
+				Delta:       mission.RewardCoin,
+				Reason:      entity.CoinReasonMissionReward,
+				ReferenceID: strconv.FormatInt(mission.ID, 10),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		claimed = mission
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// ensureMissions tを含む期間のミッションが未割り当てであれば、テンプレートから選んで割り当てる
+func (s *MissionService) ensureMissions(ctx context.Context, repo *repository.MissionRepository, userID, period string, t time.Time) error {
+	start := s.periodStart(period, t)
+	count, err := repo.CountUserMissions(ctx, userID, period, start)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	templates, err := repo.FindActiveTemplates(ctx, period)
+	if err != nil {
+		return err
+	}
+
+	n := s.config.DailyCount
+	expiresAt := s.calendar.NextDayStart(t)
+	if period == entity.MissionPeriodWeekly {
+		n = s.config.WeeklyCount
+		expiresAt = s.calendar.NextWeekStart(t)
+	}
+
+	selected := pickMissionTemplates(templates, n, missionSeed(userID, period, start))
+	missions := make([]entity.UserMission, 0, len(selected))
+	for _, tpl := range selected {
+		missions = append(missions, entity.UserMission{
+			UserID:      userID,
+			TemplateID:  tpl.ID,
+			Period:      period,
+			PeriodStart: start,
+			ExpiresAt:   expiresAt,
+			Target:      tpl.Target,
+			RewardCoin:  tpl.RewardCoin,
+		})
+	}
+	// 同時リクエストで割り当てが重複しても、同じシードから同じテンプレートが選ばれるため一意制約で吸収される
+	return repo.CreateUserMissions(ctx, missions)
+}
+
+func (s *MissionService) periodStart(period string, t time.Time) time.Time {
+	if period == entity.MissionPeriodWeekly {
+		return s.calendar.WeekStart(t)
+	}
+	return s.calendar.DayStart(t)
+}
+
+// missionSeed ユーザーと期間から、ミッション選択用の乱数シードを作る
+func missionSeed(userID, period string, periodStart time.Time) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(userID))
+	h.Write([]byte{0})
+	h.Write([]byte(period))
+	h.Write([]byte{0})
+	h.Write([]byte(periodStart.Format(time.DateOnly)))
+	return h.Sum64()
+}
+
+// pickMissionTemplates 重みに応じてテンプレートを重複なしでn件選ぶ
+func pickMissionTemplates(templates []entity.MissionTemplate, n int, seed uint64) []entity.MissionTemplate {
+	rng := rand.New(rand.NewPCG(seed, seed>>1))
+	pool := slices.Clone(templates)
+	selected := make([]entity.MissionTemplate, 0, n)
+	for len(selected) < n && len(pool) > 0 {
+		total := 0
+		for _, t := range pool {
+			total += t.Weight
+		}
+		r := rng.IntN(total)
+		for i, t := range pool {
+			r -= t.Weight
+			if r < 0 {
+				selected = append(selected, t)
+				pool = slices.Delete(pool, i, i+1)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// missionProgress ランの結果を反映した後の進捗を計算する（条件に合わないランの場合は変化なし）
+func missionProgress(tpl *entity.MissionTemplate, current int, run *entity.Run) int {
+	if tpl.Params.SpecialType != "" && run.SpecialType != tpl.Params.SpecialType {
+		return current
+	}
+	if tpl.Params.Weapon != "" && !slices.ContainsFunc(run.Weapons, func(w entity.RunSkill) bool { return w.Type == tpl.Params.Weapon }) {
+		return current
+	}
+
+	switch tpl.Objective {
+	case entity.MissionObjectiveKillCount:
+		return current + run.KillCount
+	case entity.MissionObjectiveSurvivalTime:
+		return max(current, int(math.Floor(run.SurvivalTime)))
+	case entity.MissionObjectiveClears:
+		if run.Cleared {
+			return current + 1
+		}
+	case entity.MissionObjectiveRuns:
+		return current + 1
+	}
+	return current
+}
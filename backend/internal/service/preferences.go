@@ -0,0 +1,122 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+)
+
+var ErrInvalidPreferences = errors.New("invalid preferences")
+
+// keyCodePattern KeyboardEvent.codeの形式（例: KeyW, ArrowUp, Digit1）
+var keyCodePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]{0,31}$`)
+
+var (
+	inputModes = []string{entity.InputModeKeyboard, entity.InputModeHandTracking}
+	keyActions = []string{
+		entity.KeyActionMoveUp, entity.KeyActionMoveDown, entity.KeyActionMoveLeft, entity.KeyActionMoveRight,
+		entity.KeyActionSpecial, entity.KeyActionPause,
+	}
+	languages    = []string{entity.LanguageJapanese, entity.LanguageEnglish}
+	themes       = []string{entity.ThemeLight, entity.ThemeDark, entity.ThemeSystem}
+	visibilities = []string{entity.ProfileVisibilityPublic, entity.ProfileVisibilityPrivate}
+)
+
+const (
+	minHandSensitivity  = 0.1
+	maxHandSensitivity  = 3.0
+	minCalibrationRange = 0.05
+	maxCalibrationRange = 1.0
+	minUIScale          = 0.5
+	maxUIScale          = 2.0
+)
+
+// DecodePreferences クライアントから送られた設定を読み込み、検証する
+// 省略された項目はデフォルト値となり、未知の項目が含まれる場合はエラーとする
+// キー割り当ては指定された操作のみを置き換え、省略された操作はFillDefaultKeyBindingsで補う
+func DecodePreferences(data []byte) (*entity.Preferences, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	type plain entity.Preferences
+	v := plain(entity.DefaultPreferences())
+	// versionを省略した場合は現在のバージョンとみなす
+	v.Version = 0
+	// mapは既存のキーに追加で読み込まれるため、デフォルトの割り当てと混ざらないようにする
+	v.KeyBindings = nil
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPreferences, err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("%w: unexpected data after preferences", ErrInvalidPreferences)
+	}
+
+	prefs := entity.Preferences(v)
+	prefs.FillDefaultKeyBindings()
+	if prefs.Version == 0 {
+		prefs.Version = entity.PreferencesVersion
+	}
+	if err := validatePreferences(&prefs); err != nil {
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+// validatePreferences 設定の値が取りうる範囲に収まっているかを検証する
+func validatePreferences(p *entity.Preferences) error {
+	if p.Version != entity.PreferencesVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidPreferences, p.Version)
+	}
+	if !slices.Contains(inputModes, p.InputMode) {
+		return fmt.Errorf("%w: unknown inputMode %q", ErrInvalidPreferences, p.InputMode)
+	}
+
+	// 同じキーを複数の操作に割り当てることはできない
+	assigned := make(map[string]string, len(p.KeyBindings))
+	for action, code := range p.KeyBindings {
+		if !slices.Contains(keyActions, action) {
+			return fmt.Errorf("%w: unknown key binding action %q", ErrInvalidPreferences, action)
+		}
+		if code == "" {
+			continue // 割り当てなし
+		}
+		if !keyCodePattern.MatchString(code) {
+			return fmt.Errorf("%w: invalid key code %q for %s", ErrInvalidPreferences, code, action)
+		}
+		if other, ok := assigned[code]; ok {
+			return fmt.Errorf("%w: key %q is bound to both %s and %s", ErrInvalidPreferences, code, other, action)
+		}
+		assigned[code] = action
+	}
+
+	ht := p.HandTracking
+	if ht.Sensitivity < minHandSensitivity || ht.Sensitivity > maxHandSensitivity {
+		return fmt.Errorf("%w: handTracking.sensitivity must be between %g and %g", ErrInvalidPreferences, minHandSensitivity, maxHandSensitivity)
+	}
+	if cal := ht.Calibration; cal != nil {
+		if cal.CenterX < 0 || cal.CenterX > 1 || cal.CenterY < 0 || cal.CenterY > 1 {
+			return fmt.Errorf("%w: handTracking.calibration center must be between 0 and 1", ErrInvalidPreferences)
+		}
+		if cal.Range < minCalibrationRange || cal.Range > maxCalibrationRange {
+			return fmt.Errorf("%w: handTracking.calibration.range must be between %g and %g", ErrInvalidPreferences, minCalibrationRange, maxCalibrationRange)
+		}
+	}
+
+	if !slices.Contains(languages, p.Language) {
+		return fmt.Errorf("%w: unknown language %q", ErrInvalidPreferences, p.Language)
+	}
+	if !slices.Contains(themes, p.Theme) {
+		return fmt.Errorf("%w: unknown theme %q", ErrInvalidPreferences, p.Theme)
+	}
+	if p.Display.UIScale < minUIScale || p.Display.UIScale > maxUIScale {
+		return fmt.Errorf("%w: display.uiScale must be between %g and %g", ErrInvalidPreferences, minUIScale, maxUIScale)
+	}
+	if !slices.Contains(visibilities, p.ProfileVisibility) {
+		return fmt.Errorf("%w: unknown profileVisibility %q", ErrInvalidPreferences, p.ProfileVisibility)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"errors"
+	"maps"
+	"testing"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+)
+
+// withKeyBindings デフォルトのキー割り当ての一部を置き換えたものを返す
+func withKeyBindings(overrides map[string]string) map[string]string {
+	bindings := maps.Clone(entity.DefaultPreferences().KeyBindings)
+	maps.Copy(bindings, overrides)
+	return bindings
+}
+
+func TestDecodePreferences(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           string
+		wantErr         bool
+		wantVersion     int
+		wantKeyBindings map[string]string
+	}{
+		// バージョン
+		{name: "version omitted", input: `{}`, wantVersion: entity.PreferencesVersion, wantKeyBindings: withKeyBindings(nil)},
+		{name: "version zero", input: `{"version":0}`, wantVersion: entity.PreferencesVersion},
+		{name: "current version", input: `{"version":1}`, wantVersion: entity.PreferencesVersion},
+		{name: "unsupported version", input: `{"version":2}`, wantErr: true},
+
+		// 未知の項目・余分なデータ
+		{name: "unknown field", input: `{"volume":10}`, wantErr: true},
+		{name: "unknown nested field", input: `{"display":{"uiScale":1,"fontSize":12}}`, wantErr: true},
+		{name: "trailing data", input: `{} {}`, wantErr: true},
+		{name: "not an object", input: `[]`, wantErr: true},
+
+		// キー割り当て
+		{
+			name:            "partial key bindings keep defaults",
+			input:           `{"keyBindings":{"moveUp":"ArrowUp"}}`,
+			wantKeyBindings: withKeyBindings(map[string]string{entity.KeyActionMoveUp: "ArrowUp"}),
+		},
+		{name: "unknown action", input: `{"keyBindings":{"jump":"KeyJ"}}`, wantErr: true},
+		{name: "invalid key code", input: `{"keyBindings":{"moveUp":"Key W"}}`, wantErr: true},
+		{name: "duplicate keys", input: `{"keyBindings":{"moveUp":"KeyQ","moveDown":"KeyQ"}}`, wantErr: true},
+		{name: "duplicate with explicit default", input: `{"keyBindings":{"moveUp":"KeyW","special":"KeyW"}}`, wantErr: true},
+		{
+			name:            "unbind",
+			input:           `{"keyBindings":{"pause":""}}`,
+			wantKeyBindings: withKeyBindings(map[string]string{entity.KeyActionPause: ""}),
+		},
+		{
+			name:  "unbind several",
+			input: `{"keyBindings":{"moveUp":"","moveDown":""}}`,
+			wantKeyBindings: withKeyBindings(map[string]string{
+				entity.KeyActionMoveUp:   "",
+				entity.KeyActionMoveDown: "",
+			}),
+		},
+		{
+			// moveDownのデフォルト（KeyS）は使われているため割り当てなしとなる
+			name:  "default key collision",
+			input: `{"keyBindings":{"moveUp":"KeyS"}}`,
+			wantKeyBindings: withKeyBindings(map[string]string{
+				entity.KeyActionMoveUp:   "KeyS",
+				entity.KeyActionMoveDown: "",
+			}),
+		},
+		{
+			name:  "swap keys",
+			input: `{"keyBindings":{"moveUp":"KeyS","moveDown":"KeyW"}}`,
+			wantKeyBindings: withKeyBindings(map[string]string{
+				entity.KeyActionMoveUp:   "KeyS",
+				entity.KeyActionMoveDown: "KeyW",
+			}),
+		},
+
+		// 値の範囲
+		{name: "unknown input mode", input: `{"inputMode":"gamepad"}`, wantErr: true},
+		{name: "ui scale out of range", input: `{"display":{"uiScale":5}}`, wantErr: true},
+		{name: "unknown visibility", input: `{"profileVisibility":"friends"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefs, err := DecodePreferences([]byte(tt.input))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPreferences) {
+					t.Fatalf("DecodePreferences(%s) error = %v, want %v", tt.input, err, ErrInvalidPreferences)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodePreferences(%s) error = %v", tt.input, err)
+			}
+			if tt.wantVersion != 0 && prefs.Version != tt.wantVersion {
+				t.Errorf("Version = %d, want %d", prefs.Version, tt.wantVersion)
+			}
+			if tt.wantKeyBindings != nil && !maps.Equal(prefs.KeyBindings, tt.wantKeyBindings) {
+				t.Errorf("KeyBindings = %v, want %v", prefs.KeyBindings, tt.wantKeyBindings)
+			}
+		})
+	}
+}
+
+func TestFillDefaultKeyBindings(t *testing.T) {
+	tests := []struct {
+		name  string
+		input map[string]string
+		want  map[string]string
+	}{
+		{name: "nil", input: nil, want: withKeyBindings(nil)},
+		{name: "empty", input: map[string]string{}, want: withKeyBindings(nil)},
+		{
+			name:  "unbound action stays unbound",
+			input: map[string]string{entity.KeyActionSpecial: ""},
+			want:  withKeyBindings(map[string]string{entity.KeyActionSpecial: ""}),
+		},
+		{
+			name:  "default key used by another action",
+			input: map[string]string{entity.KeyActionPause: "Space"},
+			want: withKeyBindings(map[string]string{
+				entity.KeyActionPause:   "Space",
+				entity.KeyActionSpecial: "",
+			}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := entity.Preferences{KeyBindings: tt.input}
+			p.FillDefaultKeyBindings()
+			if !maps.Equal(p.KeyBindings, tt.want) {
+				t.Errorf("KeyBindings = %v, want %v", p.KeyBindings, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePreferencesDefaults(t *testing.T) {
+	prefs := entity.DefaultPreferences()
+	if err := validatePreferences(&prefs); err != nil {
+		t.Fatalf("validatePreferences(DefaultPreferences()) = %v", err)
+	}
+}
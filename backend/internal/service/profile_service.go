@@ -0,0 +1,389 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/repository"
+	"github.com/uptrace/bun"
+)
+
+const (
+	minDisplayNameLength = 2
+	maxDisplayNameLength = 16
+)
+
+var (
+	ErrInvalidDisplayName    = errors.New("invalid display name")
+	ErrDisplayNameNotAllowed = errors.New("display name is not allowed")
+	ErrDisplayNameTaken      = errors.New("display name is already taken")
+	ErrInvalidAvatar         = errors.New("invalid avatar")
+	ErrRenameCooldown        = errors.New("display name was changed recently")
+	ErrPlayerNotFound        = errors.New("player not found")
+)
+
+// RenameCooldownError 表示名を変更できるようになるまでの時間
+type RenameCooldownError struct {
+	AvailableAt time.Time
+}
+
+func (e *RenameCooldownError) Error() string {
+	return fmt.Sprintf("%s: can be changed again at %s", ErrRenameCooldown, e.AvailableAt.Format(time.RFC3339))
+}
+
+func (e *RenameCooldownError) Unwrap() error {
+	return ErrRenameCooldown
+}
+
+// defaultDisplayNameBlockedWords 表示名に単語として含めることができない語（DISPLAY_NAME_BLOCKLISTで追加できる）
+// 短い英単語は他の単語の一部に含まれやすい（Grape・Badmintonなど）ため、単語全体が一致する場合のみ禁止する
+// 運営を装う名前もここで防ぐ
+var defaultDisplayNameBlockedWords = []string{
+	"admin", "moderator", "official",
+	"fucker", "fucking", "shit", "bitch", "cunt", "asshole", "whore", "slut", "rape", "nazi",
+}
+
+// defaultDisplayNameBlockedSubstrings 表示名のどこに含まれていても禁止する語（DISPLAY_NAME_BLOCKLIST_SUBSTRINGSで追加できる）
+// 単語の区切りがない日本語と、普通の語の一部になることがない語のみを置く
+var defaultDisplayNameBlockedSubstrings = []string{
+	"fuck", "nigger", "faggot",
+	"運営", "死ね", "殺す", "きちがい", "ちんこ", "まんこ",
+}
+
+// ProfileConfig 表示名の変更に関する設定
+type ProfileConfig struct {
+	UniqueDisplayNames bool          // 他のユーザーと同じ表示名を禁止する
+	RenameCooldown     time.Duration // 表示名を変更してから次に変更できるまでの時間
+	BlockedWords       []string      // 単語全体が一致する場合に禁止する語
+	BlockedSubstrings  []string      // 表示名の一部に含まれる場合に禁止する語
+}
+
+// LoadProfileConfig 環境変数から設定を読み込む（未設定の項目はデフォルト値）
+func LoadProfileConfig() ProfileConfig {
+	return ProfileConfig{
+		UniqueDisplayNames: envBool("DISPLAY_NAME_UNIQUE", false),
+		RenameCooldown:     envDuration("DISPLAY_NAME_COOLDOWN", 7*24*time.Hour),
+		BlockedWords:       append(slices.Clone(defaultDisplayNameBlockedWords), envList("DISPLAY_NAME_BLOCKLIST")...),
+		BlockedSubstrings:  append(slices.Clone(defaultDisplayNameBlockedSubstrings), envList("DISPLAY_NAME_BLOCKLIST_SUBSTRINGS")...),
+	}
+}
+
+// ProfileUpdate プロフィールの変更内容（nilの項目は変更しない、空文字の場合は設定を解除する）
+type ProfileUpdate struct {
+	DisplayName *string
+	Avatar      *string
+}
+
+// PublicProfile 他のプレイヤーに見せるプロフィール（メールアドレスなどの個人情報は含めない）
+// 非公開に設定している場合は名前とアバターのみ
+type PublicProfile struct {
+	UserID         string              `json:"userId"`
+	DisplayName    string              `json:"displayName"`
+	Avatar         string              `json:"avatar,omitempty"`
+	AvatarURL      string              `json:"avatarUrl,omitempty"`
+	IsPrivate      bool                `json:"isPrivate"`
+	Records        *PlayerRecords      `json:"records,omitempty"`
+	Achievements   []PlayerAchievement `json:"achievements,omitempty"`
+	FavoriteWeapon *string             `json:"favoriteWeapon,omitempty"`
+}
+
+// PlayerRecords プロフィールに載せる自己ベスト
+type PlayerRecords struct {
+	TotalRuns        int      `json:"totalRuns"`
+	Clears           int      `json:"clears"`
+	BestSurvivalTime float64  `json:"bestSurvivalTime"`
+	FastestClear     *float64 `json:"fastestClear"`
+	BestKillCount    int      `json:"bestKillCount"`
+	BestLevel        int      `json:"bestLevel"`
+}
+
+// PlayerAchievement プロフィールに載せる解除済みの実績
+type PlayerAchievement struct {
+	Code        string    `json:"code"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	UnlockedAt  time.Time `json:"unlockedAt"`
+}
+
+type ProfileService struct {
+	userRepo          *repository.UserRepository
+	settingsRepo      *repository.SettingsRepository
+	runRepo           *repository.RunRepository
+	achievementRepo   *repository.AchievementRepository
+	txManager         *repository.TxManager
+	config            ProfileConfig
+	blockedWords      []string
+	blockedSubstrings []string
+}
+
+func NewProfileService(userRepo *repository.UserRepository, settingsRepo *repository.SettingsRepository, runRepo *repository.RunRepository, achievementRepo *repository.AchievementRepository, txManager *repository.TxManager, config ProfileConfig) *ProfileService {
+	return &ProfileService{
+		userRepo:          userRepo,
+		settingsRepo:      settingsRepo,
+		runRepo:           runRepo,
+		achievementRepo:   achievementRepo,
+		txManager:         txManager,
+		config:            config,
+		blockedWords:      normalizeBlocklist(config.BlockedWords),
+		blockedSubstrings: normalizeBlocklist(config.BlockedSubstrings),
+	}
+}
+
+// GetPlayer プレイヤーの公開プロフィールを取得する
+// 削除を申請したプレイヤーは存在しないものとして扱い、本人は非公開の設定でも全て見られる
+func (s *ProfileService) GetPlayer(ctx context.Context, viewerID, playerID string) (*PublicProfile, error) {
+	user, err := s.userRepo.FindByID(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.DeletedAt != nil {
+		return nil, ErrPlayerNotFound
+	}
+
+	profile := &PublicProfile{
+		UserID:      user.ID,
+		DisplayName: user.PublicName(),
+		Avatar:      user.Avatar,
+		AvatarURL:   user.PublicAvatarURL(),
+	}
+
+	settings, err := s.settingsRepo.GetByUserID(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	if settings != nil && settings.Preferences.ProfileVisibility == entity.ProfileVisibilityPrivate && viewerID != playerID {
+		profile.IsPrivate = true
+		return profile, nil
+	}
+
+	stats, err := s.runRepo.AggregateStats(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	profile.Records = &PlayerRecords{
+		TotalRuns:        stats.TotalRuns,
+		Clears:           stats.Clears,
+		BestSurvivalTime: stats.BestSurvivalTime,
+		FastestClear:     stats.FastestClear,
+		BestKillCount:    stats.BestKillCount,
+		BestLevel:        stats.BestLevel,
+	}
+
+	weapon, err := s.runRepo.FindMostPickedSkill(ctx, playerID, "weapons")
+	if err != nil {
+		return nil, err
+	}
+	if weapon != "" {
+		profile.FavoriteWeapon = &weapon
+	}
+
+	achievements, err := s.achievementRepo.FindActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	unlocked, err := s.achievementRepo.FindUnlockedByUserID(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	unlockedAt := make(map[int]time.Time, len(unlocked))
+	for _, ua := range unlocked {
+		unlockedAt[ua.AchievementID] = ua.UnlockedAt
+	}
+	profile.Achievements = []PlayerAchievement{}
+	for _, a := range achievements {
+		if t, ok := unlockedAt[a.ID]; ok {
+			profile.Achievements = append(profile.Achievements, PlayerAchievement{
+				Code:        a.Code,
+				Name:        a.Name,
+				Description: a.Description,
+				UnlockedAt:  t,
+			})
+		}
+	}
+	return profile, nil
+}
+
+// UpdateProfile 表示名とアバターを変更する
+// 表示名を解除した場合はIDから作った名前に戻り、変更の間隔の制限は解除しても変わらない
+func (s *ProfileService) UpdateProfile(ctx context.Context, userID string, update ProfileUpdate) (*entity.User, error) {
+	var displayName string
+	if update.DisplayName != nil {
+		displayName = strings.TrimSpace(*update.DisplayName)
+		if displayName != "" {
+			if err := s.validateDisplayName(displayName); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if update.Avatar != nil && *update.Avatar != "" && *update.Avatar != entity.AvatarGoogle && !slices.Contains(entity.AvatarPresets, *update.Avatar) {
+		return nil, fmt.Errorf("%w: unknown avatar %q", ErrInvalidAvatar, *update.Avatar)
+	}
+
+	var user *entity.User
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		userRepo := s.userRepo.WithTx(tx)
+
+		var err error
+		user, err = userRepo.FindByIDForUpdate(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return ErrUserNotFound
+		}
+
+		if update.DisplayName != nil && displayName != user.DisplayName {
+			if err := s.checkRename(ctx, userRepo, user, displayName); err != nil {
+				return err
+			}
+			if displayName != "" {
+				now := time.Now()
+				user.DisplayNameChangedAt = &now
+			}
+			user.DisplayName = displayName
+		}
+		if update.Avatar != nil {
+			user.Avatar = *update.Avatar
+		}
+		return userRepo.UpdateProfile(ctx, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// checkRename 表示名の変更の間隔と、他のユーザーとの重複を確認する
+func (s *ProfileService) checkRename(ctx context.Context, userRepo *repository.UserRepository, user *entity.User, displayName string) error {
+	if displayName == "" {
+		return nil
+	}
+	if user.DisplayNameChangedAt != nil {
+		availableAt := user.DisplayNameChangedAt.Add(s.config.RenameCooldown)
+		if time.Now().Before(availableAt) {
+			return &RenameCooldownError{AvailableAt: availableAt}
+		}
+	}
+	if !s.config.UniqueDisplayNames {
+		return nil
+	}
+
+	// 同じ表示名への変更が同時に行われても、どちらか一方だけが成功するようにする
+	if err := userRepo.LockDisplayName(ctx, displayName); err != nil {
+		return err
+	}
+	taken, err := userRepo.ExistsDisplayName(ctx, displayName, user.ID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrDisplayNameTaken
+	}
+	return nil
+}
+
+// validateDisplayName 表示名の長さ・使える文字・禁止語を確認する
+// 文字・数字・空白（連続しないもの）・「_-.」のみを使える
+func (s *ProfileService) validateDisplayName(name string) error {
+	n := utf8.RuneCountInString(name)
+	if n < minDisplayNameLength || n > maxDisplayNameLength {
+		return fmt.Errorf("%w: must be %d-%d characters", ErrInvalidDisplayName, minDisplayNameLength, maxDisplayNameLength)
+	}
+	prevSpace := false
+	for _, r := range name {
+		switch {
+		case r == ' ':
+			if prevSpace {
+				return fmt.Errorf("%w: must not contain consecutive spaces", ErrInvalidDisplayName)
+			}
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '_', r == '-', r == '.':
+		default:
+			return fmt.Errorf("%w: must not contain %q", ErrInvalidDisplayName, r)
+		}
+		prevSpace = r == ' '
+	}
+
+	for _, token := range moderationTokens(name) {
+		for _, candidate := range tokenVariants(token) {
+			if slices.Contains(s.blockedWords, candidate) {
+				return ErrDisplayNameNotAllowed
+			}
+		}
+	}
+	normalized := normalizeForModeration(name)
+	for _, word := range s.blockedSubstrings {
+		if strings.Contains(normalized, word) {
+			return ErrDisplayNameNotAllowed
+		}
+	}
+	return nil
+}
+
+// normalizeBlocklist 禁止語を判定に使う形にそろえる
+func normalizeBlocklist(words []string) []string {
+	list := make([]string, 0, len(words))
+	for _, word := range words {
+		if w := normalizeForModeration(word); w != "" {
+			list = append(list, w)
+		}
+	}
+	return list
+}
+
+// moderationTokens 表示名を単語に分ける
+// 文字・数字以外の文字と、小文字から大文字に変わる位置（AdminBot → Admin, Bot）で区切る
+func moderationTokens(name string) []string {
+	runes := []rune(name)
+	var tokens []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			tokens = append(tokens, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && len(current) > 0 {
+			prev := current[len(current)-1]
+			// 大文字が続いた後の単語の先頭（XMLAdmin → XML, Admin）でも区切る
+			if unicode.IsLower(prev) || (unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+	return tokens
+}
+
+// tokenVariants 単語を禁止語と比べる際の候補を返す
+// 数字を文字に戻したもの（sh1t → shit）と、末尾の数字を除いたもの（admin01 → admin）も含める
+func tokenVariants(token string) []string {
+	trimmed := strings.TrimRightFunc(token, unicode.IsDigit)
+	return []string{token, leetReplacer.Replace(token), trimmed, leetReplacer.Replace(trimmed)}
+}
+
+// leetReplacer 禁止語の判定で、文字の代わりに使われやすい数字を元の文字に戻す
+var leetReplacer = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t")
+
+// normalizeForModeration 禁止語の判定のため、小文字にして文字・数字以外を取り除く
+func normalizeForModeration(s string) string {
+	s = leetReplacer.Replace(strings.ToLower(s))
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, s)
+}
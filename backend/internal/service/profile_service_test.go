@@ -0,0 +1,113 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestValidateDisplayName(t *testing.T) {
+	s := NewProfileService(nil, nil, nil, nil, nil, ProfileConfig{
+		BlockedWords:      defaultDisplayNameBlockedWords,
+		BlockedSubstrings: defaultDisplayNameBlockedSubstrings,
+	})
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr error
+	}{
+		// 禁止語を一部に含むだけの普通の名前
+		{"grape", "Grape", nil},
+		{"badminton", "Badminton", nil},
+		{"shiitake", "Shiitake", nil},
+		{"scunthorpe", "Scunthorpe", nil},
+		{"therapist", "Therapist", nil},
+		{"digits in name", "Player1337", nil},
+		{"japanese", "たろう", nil},
+		{"separators", "tri_survivor-01", nil},
+
+		// 単語全体が禁止語
+		{"word", "admin", ErrDisplayNameNotAllowed},
+		{"upper case", "ADMIN", ErrDisplayNameNotAllowed},
+		{"separated by space", "the admin", ErrDisplayNameNotAllowed},
+		{"separated by symbol", "game.admin", ErrDisplayNameNotAllowed},
+		{"camel case", "AdminBot", ErrDisplayNameNotAllowed},
+		{"after acronym", "TRIAdmin", ErrDisplayNameNotAllowed},
+		{"trailing digits", "admin01", ErrDisplayNameNotAllowed},
+		{"leet", "sh1t", ErrDisplayNameNotAllowed},
+		{"leet and separator", "5h1t_head", ErrDisplayNameNotAllowed},
+
+		// 一部に含まれていれば禁止する語
+		{"substring", "xxfuckxx", ErrDisplayNameNotAllowed},
+		{"japanese substring", "運営チーム", ErrDisplayNameNotAllowed},
+		{"japanese with separator", "死.ね", ErrDisplayNameNotAllowed},
+
+		// 長さ・使える文字
+		{"too short", "a", ErrInvalidDisplayName},
+		{"too long", "abcdefghijklmnopq", ErrInvalidDisplayName},
+		{"consecutive spaces", "a  b", ErrInvalidDisplayName},
+		{"symbol", "a@b", ErrInvalidDisplayName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.validateDisplayName(tt.input)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("validateDisplayName(%q) = %v, want nil", tt.input, err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("validateDisplayName(%q) = %v, want %v", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateDisplayNameConfiguredWords(t *testing.T) {
+	s := NewProfileService(nil, nil, nil, nil, nil, ProfileConfig{
+		BlockedWords:      []string{" Spam "},
+		BlockedSubstrings: []string{"EVIL"},
+	})
+
+	tests := []struct {
+		input   string
+		allowed bool
+	}{
+		{"spam", false},
+		{"Spam King", false},
+		{"Spammer", true},
+		{"evil", false},
+		{"Devilish", false},
+		{"grape", true},
+	}
+	for _, tt := range tests {
+		err := s.validateDisplayName(tt.input)
+		if tt.allowed && err != nil {
+			t.Errorf("validateDisplayName(%q) = %v, want nil", tt.input, err)
+		}
+		if !tt.allowed && !errors.Is(err, ErrDisplayNameNotAllowed) {
+			t.Errorf("validateDisplayName(%q) = %v, want %v", tt.input, err, ErrDisplayNameNotAllowed)
+		}
+	}
+}
+
+func TestModerationTokens(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"Grape", []string{"grape"}},
+		{"AdminBot", []string{"admin", "bot"}},
+		{"XMLAdmin", []string{"xml", "admin"}},
+		{"the_admin-01", []string{"the", "admin", "01"}},
+		{"sh1t", []string{"sh1t"}},
+		{"たろう 運営", []string{"たろう", "運営"}},
+	}
+	for _, tt := range tests {
+		if got := moderationTokens(tt.input); !slices.Equal(got, tt.want) {
+			t.Errorf("moderationTokens(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
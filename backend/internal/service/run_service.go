@@ -0,0 +1,350 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/repository"
+	"github.com/uptrace/bun"
+)
+
+// GameClearTime クリアとなる生存時間（秒）。クライアントのGAME_CLEAR_TIMEと一致させること
+const GameClearTime = 333
+
+var (
+	ErrRunNotFound          = errors.New("run not found")
+	ErrRunAlreadyFinished   = errors.New("run already finished")
+	ErrRunNonceMismatch     = errors.New("run nonce mismatch")
+	ErrRunAbortWindowClosed = errors.New("run abort window closed")
+)
+
+// RunConfig ランの進行に関する設定
+type RunConfig struct {
+	// AbortGracePeriod ラン開始後、中止して消費アイテムを返却できる期間
+	AbortGracePeriod time.Duration
+}
+
+// LoadRunConfig 環境変数から設定を読み込む（未設定の項目はデフォルト値）
+func LoadRunConfig() RunConfig {
+	return RunConfig{
+		AbortGracePeriod: envDuration("RUN_ABORT_GRACE_PERIOD", 60*time.Second),
+	}
+}
+
+type RunService struct {
+	repo               *repository.RunRepository
+	reviewRepo         *repository.RunReviewRepository
+	validator          *RunValidator
+	userService        *UserService
+	leaderboardService *LeaderboardService
+	achievementService *AchievementService
+	unlockService      *UnlockService
+	missionService     *MissionService
+	loadoutService     *LoadoutService
+	consumableService  *ConsumableService
+	config             RunConfig
+	txManager          *repository.TxManager
+}
+
+func NewRunService(repo *repository.RunRepository, reviewRepo *repository.RunReviewRepository, validator *RunValidator, userService *UserService, leaderboardService *LeaderboardService, achievementService *AchievementService, unlockService *UnlockService, missionService *MissionService, loadoutService *LoadoutService, consumableService *ConsumableService, config RunConfig, txManager *repository.TxManager) *RunService {
+	return &RunService{repo: repo, reviewRepo: reviewRepo, validator: validator, userService: userService, leaderboardService: leaderboardService, achievementService: achievementService, unlockService: unlockService, missionService: missionService, loadoutService: loadoutService, consumableService: consumableService, config: config, txManager: txManager}
+}
+
+// RunResult クライアントから送信されるランの結果（PlayerStatsの要約）
+type RunResult struct {
+	Nonce       string
+	Time        float64
+	KillCount   int
+	Level       int
+	Coins       int
+	Weapons     []entity.RunSkill
+	Passives    []entity.RunSkill
+	SpecialType string
+}
+
+// RunStart ラン開始時の結果（ランの記録と、持ち込んで消費したアイテム）
+type RunStart struct {
+	*entity.Run
+	Consumables []entity.RunConsumable `json:"consumables"`
+}
+
+// RunSummary ラン終了時の結果（ランの記録と、このランで消費したアイテム・解除した実績・解放した武器と必殺技）
+type RunSummary struct {
+	*entity.Run
+	Consumables          []entity.RunConsumable `json:"consumables"`
+	UnlockedAchievements []entity.Achievement   `json:"unlockedAchievements"`
+	UnlockedSkills       []entity.Unlockable    `json:"unlockedSkills"`
+}
+
+// StartRun ランを開始し、結果送信時に必要なnonceを発行する
+// loadoutNameを指定した場合は、その装備プリセットをランに記録し、持ち込むアイテムを消費する
+func (s *RunService) StartRun(ctx context.Context, userID, loadoutName string) (*RunStart, error) {
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	var loadout *entity.LoadoutSnapshot
+	if loadoutName != "" {
+		loadout, err = s.loadoutService.SnapshotPreset(ctx, userID, loadoutName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	run := &entity.Run{
+		UserID:   userID,
+		Nonce:    nonce,
+		Status:   entity.RunStatusInProgress,
+		Level:    1,
+		Weapons:  []entity.RunSkill{},
+		Passives: []entity.RunSkill{},
+		Loadout:  loadout,
+	}
+	start := &RunStart{Run: run, Consumables: []entity.RunConsumable{}}
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		if err := s.repo.WithTx(tx).Create(ctx, run); err != nil {
+			return err
+		}
+		if loadout == nil || len(loadout.Consumables) == 0 {
+			return nil
+		}
+		consumables, err := s.consumableService.ConsumeForRunTx(ctx, tx, userID, run.ID, loadout.Consumables)
+		if err != nil {
+			return err
+		}
+		start.Consumables = consumables
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return start, nil
+}
+
+// AbortRun 開始直後のランを中止し、消費したアイテムを返却する
+// 中止できるのはラン開始からAbortGracePeriod以内に限る
+func (s *RunService) AbortRun(ctx context.Context, userID, runID, nonce string) (*RunStart, error) {
+	var aborted *RunStart
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		runRepo := s.repo.WithTx(tx)
+
+		run, err := runRepo.FindByIDForUpdate(ctx, runID, userID)
+		if err != nil {
+			return err
+		}
+		if run == nil {
+			return ErrRunNotFound
+		}
+		if run.Status != entity.RunStatusInProgress {
+			return ErrRunAlreadyFinished
+		}
+		if subtle.ConstantTimeCompare([]byte(run.Nonce), []byte(nonce)) != 1 {
+			return ErrRunNonceMismatch
+		}
+		now := time.Now()
+		if now.Sub(run.StartedAt) > s.config.AbortGracePeriod {
+			return ErrRunAbortWindowClosed
+		}
+
+		run.Status = entity.RunStatusAborted
+		run.FinishedAt = &now
+		if err := runRepo.UpdateResult(ctx, run); err != nil {
+			return err
+		}
+
+		refunded, err := s.consumableService.RefundRunTx(ctx, tx, userID, run.ID)
+		if err != nil {
+			return err
+		}
+		aborted = &RunStart{Run: run, Consumables: refunded}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return aborted, nil
+}
+
+// FinishRun ランの結果を保存し、獲得コインを付与する
+// 1つのランにつき報酬の付与は一度だけ行われる
+// 実現不可能な結果の場合は報酬を付与せず、審査待ちとして記録する
+func (s *RunService) FinishRun(ctx context.Context, userID, runID string, result RunResult) (*RunSummary, error) {
+	summary := &RunSummary{Consumables: []entity.RunConsumable{}, UnlockedAchievements: []entity.Achievement{}, UnlockedSkills: []entity.Unlockable{}}
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		runRepo := s.repo.WithTx(tx)
+
+		// 同じランの結果が同時に送信された場合に備えて行ロックを取得する
+		run, err := runRepo.FindByIDForUpdate(ctx, runID, userID)
+		if err != nil {
+			return err
+		}
+		if run == nil {
+			return ErrRunNotFound
+		}
+		if run.Status != entity.RunStatusInProgress {
+			return ErrRunAlreadyFinished
+		}
+		if subtle.ConstantTimeCompare([]byte(run.Nonce), []byte(result.Nonce)) != 1 {
+			return ErrRunNonceMismatch
+		}
+
+		now := time.Now()
+		reasons := s.validator.Validate(run, result, now)
+
+		run.Status = entity.RunStatusFinished
+		run.FinishedAt = &now
+		run.SurvivalTime = result.Time
+		run.KillCount = result.KillCount
+		run.Level = result.Level
+		run.Coins = result.Coins
+		run.Weapons = result.Weapons
+		run.Passives = result.Passives
+		run.SpecialType = result.SpecialType
+		run.Cleared = result.Time >= GameClearTime
+		run.CoinReward = result.Coins
+
+		consumables, err := s.consumableService.FindForRunTx(ctx, tx, run.ID)
+		if err != nil {
+			return err
+		}
+		summary.Consumables = consumables
+		if hasConsumableEffect(consumables, entity.ConsumableEffectCoinDoubler) {
+			run.CoinReward *= 2
+		}
+
+		if len(reasons) > 0 {
+			run.Status = entity.RunStatusFlagged
+			run.CoinReward = 0
+		}
+
+		if run.CoinReward > 0 {
+			_, err := s.userService.ApplyCoinChangeTx(ctx, tx, userID, CoinChange{
+				Delta:       run.CoinReward,
+				Reason:      entity.CoinReasonRunReward,
+				ReferenceID: run.ID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := runRepo.UpdateResult(ctx, run); err != nil {
+			return err
+		}
+
+		if err := s.leaderboardService.RecordRunTx(ctx, tx, run); err != nil {
+			return err
+		}
+
+		if run.Status == entity.RunStatusFlagged {
+			review := &entity.RunReview{
+				RunID:   run.ID,
+				UserID:  userID,
+				Reasons: reasons,
+				Status:  entity.RunReviewStatusPending,
+			}
+			if err := s.reviewRepo.WithTx(tx).Create(ctx, review); err != nil {
+				return err
+			}
+		} else {
+			unlocked, err := s.achievementService.EvaluateRunTx(ctx, tx, userID, run.ID)
+			if err != nil {
+				return err
+			}
+			summary.UnlockedAchievements = unlocked
+
+			// 今回のランの記録と解除した実績を含めて、武器・必殺技の解放条件を判定する
+			skills, err := s.unlockService.EvaluateUnlocksTx(ctx, tx, userID)
+			if err != nil {
+				return err
+			}
+			summary.UnlockedSkills = skills
+
+			if err := s.missionService.ApplyRunTx(ctx, tx, run); err != nil {
+				return err
+			}
+		}
+		summary.Run = run
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// RunHistory ラン履歴の1ページ分
+type RunHistory struct {
+	Runs       []entity.Run `json:"runs"`
+	NextCursor *string      `json:"nextCursor"`
+}
+
+// ListRuns 終了済みのランを新しい順に取得する
+// filter.BeforeIDには前ページのNextCursorを指定する
+func (s *RunService) ListRuns(ctx context.Context, filter repository.RunFilter) (*RunHistory, error) {
+	if filter.BeforeID != "" {
+		exists, err := s.repo.ExistsCompleted(ctx, filter.BeforeID, filter.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	// 次ページの有無を判定するため1件多く取得する
+	limit := filter.Limit
+	filter.Limit = limit + 1
+	runs, err := s.repo.FindCompleted(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &RunHistory{Runs: runs}
+	if len(runs) > limit {
+		history.Runs = runs[:limit]
+		next := runs[limit-1].ID
+		history.NextCursor = &next
+	}
+	return history, nil
+}
+
+// GetCareerStats 保存されたランから通算成績を集計する
+func (s *RunService) GetCareerStats(ctx context.Context, userID string) (*entity.CareerStats, error) {
+	stats, err := s.repo.AggregateStats(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	weapon, err := s.repo.FindMostPickedSkill(ctx, userID, "weapons")
+	if err != nil {
+		return nil, err
+	}
+	if weapon != "" {
+		stats.FavoriteWeapon = &weapon
+	}
+
+	passive, err := s.repo.FindMostPickedSkill(ctx, userID, "passives")
+	if err != nil {
+		return nil, err
+	}
+	if passive != "" {
+		stats.MostPickedPassive = &passive
+	}
+	return stats, nil
+}
+
+// generateNonce ランごとのランダムなnonceを生成する
+func generateNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
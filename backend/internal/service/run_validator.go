@@ -0,0 +1,135 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+)
+
+// RunValidationConfig ラン結果の妥当性チェックに使う閾値
+type RunValidationConfig struct {
+	// WallClockSlack 申告された生存時間が、ラン開始からの実経過時間をどれだけ超えてよいか
+	WallClockSlack time.Duration
+	// ClearTimeSlack 生存時間がGameClearTimeをどれだけ超えてよいか（クリア時点でゲームは終了する）
+	ClearTimeSlack float64
+	// MaxKillsPerSecond 生存時間1秒あたりの最大撃破数
+	MaxKillsPerSecond float64
+	// KillAllowance 撃破数の上限に加算する固定値（序盤の必殺技などによる揺れを吸収する）
+	KillAllowance int
+	// MaxCoinsPerSecond 生存時間1秒あたりの最大獲得コイン数（敵のドロップ分）
+	MaxCoinsPerSecond float64
+	// CoinsPerLevelUp レベルアップ1回あたりに獲得しうる最大コイン数（GET_COINスキル分）
+	CoinsPerLevelUp int
+	// MaxExpPerSecond 生存時間1秒あたりの最大獲得経験値
+	MaxExpPerSecond float64
+}
+
+// DefaultRunValidationConfig デフォルトの閾値を返す
+func DefaultRunValidationConfig() RunValidationConfig {
+	return RunValidationConfig{
+		WallClockSlack:    10 * time.Second,
+		ClearTimeSlack:    5,
+		MaxKillsPerSecond: 20,
+		KillAllowance:     100,
+		MaxCoinsPerSecond: 10,
+		CoinsPerLevelUp:   50,
+		MaxExpPerSecond:   100,
+	}
+}
+
+// LoadRunValidationConfig 環境変数から閾値を読み込む（未設定の項目はデフォルト値）
+func LoadRunValidationConfig() RunValidationConfig {
+	def := DefaultRunValidationConfig()
+	return RunValidationConfig{
+		WallClockSlack:    envDuration("RUN_VALIDATION_WALL_CLOCK_SLACK", def.WallClockSlack),
+		ClearTimeSlack:    envFloat("RUN_VALIDATION_CLEAR_TIME_SLACK", def.ClearTimeSlack),
+		MaxKillsPerSecond: envFloat("RUN_VALIDATION_MAX_KILLS_PER_SECOND", def.MaxKillsPerSecond),
+		KillAllowance:     envInt("RUN_VALIDATION_KILL_ALLOWANCE", def.KillAllowance),
+		MaxCoinsPerSecond: envFloat("RUN_VALIDATION_MAX_COINS_PER_SECOND", def.MaxCoinsPerSecond),
+		CoinsPerLevelUp:   envInt("RUN_VALIDATION_COINS_PER_LEVEL_UP", def.CoinsPerLevelUp),
+		MaxExpPerSecond:   envFloat("RUN_VALIDATION_MAX_EXP_PER_SECOND", def.MaxExpPerSecond),
+	}
+}
+
+// RunValidator 送信されたラン結果が実現可能な範囲に収まっているかを検証する
+type RunValidator struct {
+	config RunValidationConfig
+}
+
+func NewRunValidator(config RunValidationConfig) *RunValidator {
+	return &RunValidator{config: config}
+}
+
+// Validate ラン結果を検証し、不審な点があればその理由を返す（問題なければ空）
+func (v *RunValidator) Validate(run *entity.Run, result RunResult, now time.Time) []string {
+	var reasons []string
+
+	elapsed := now.Sub(run.StartedAt) + v.config.WallClockSlack
+	if result.Time > elapsed.Seconds() {
+		reasons = append(reasons, fmt.Sprintf("survival time %.1fs exceeds elapsed wall-clock time %.1fs", result.Time, now.Sub(run.StartedAt).Seconds()))
+	}
+
+	if result.Time > GameClearTime+v.config.ClearTimeSlack {
+		reasons = append(reasons, fmt.Sprintf("survival time %.1fs exceeds game clear time %ds", result.Time, GameClearTime))
+	}
+
+	maxKills := int(math.Ceil(result.Time*v.config.MaxKillsPerSecond)) + v.config.KillAllowance
+	if result.KillCount > maxKills {
+		reasons = append(reasons, fmt.Sprintf("kill count %d exceeds limit %d", result.KillCount, maxKills))
+	}
+
+	maxCoins := int(math.Ceil(result.Time*v.config.MaxCoinsPerSecond)) + (result.Level-1)*v.config.CoinsPerLevelUp
+	if result.Coins > maxCoins {
+		reasons = append(reasons, fmt.Sprintf("coins %d exceed limit %d", result.Coins, maxCoins))
+	}
+
+	maxExp := result.Time * v.config.MaxExpPerSecond
+	if required := requiredExpForLevel(result.Level); float64(required) > maxExp {
+		reasons = append(reasons, fmt.Sprintf("level %d requires %d exp, limit is %.0f", result.Level, required, maxExp))
+	}
+
+	reasons = append(reasons, validateSkills("weapon", result.Weapons, entity.WeaponMaxLevels)...)
+	reasons = append(reasons, validateSkills("passive", result.Passives, entity.PassiveMaxLevels)...)
+
+	if result.SpecialType != "" && !entity.IsSpecialSkill(result.SpecialType) {
+		reasons = append(reasons, fmt.Sprintf("unknown special skill %q", result.SpecialType))
+	}
+
+	return reasons
+}
+
+// validateSkills スキルの種類とレベルが定義の範囲内かを検証する
+func validateSkills(kind string, skills []entity.RunSkill, maxLevels map[string]int) []string {
+	var reasons []string
+	seen := make(map[string]bool, len(skills))
+	for _, s := range skills {
+		maxLevel, ok := maxLevels[s.Type]
+		if !ok {
+			reasons = append(reasons, fmt.Sprintf("unknown %s %q", kind, s.Type))
+			continue
+		}
+		if seen[s.Type] {
+			reasons = append(reasons, fmt.Sprintf("duplicate %s %q", kind, s.Type))
+		}
+		seen[s.Type] = true
+		if s.Level < 1 || s.Level > maxLevel {
+			reasons = append(reasons, fmt.Sprintf("%s %q level %d is out of range 1-%d", kind, s.Type, s.Level, maxLevel))
+		}
+	}
+	return reasons
+}
+
+// requiredExpForLevel レベル1から指定レベルに到達するまでに必要な累計経験値
+// クライアントのPlayer.calculateNextLevelExp (floor(10 * 1.3^(level-1))) と一致させること
+func requiredExpForLevel(level int) int {
+	total := 0
+	for l := 1; l < level; l++ {
+		total += int(math.Floor(10 * math.Pow(1.3, float64(l-1))))
+		if total > math.MaxInt32 {
+			return math.MaxInt32
+		}
+	}
+	return total
+}
@@ -1,18 +1,59 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
 	"github.com/RiTa-23/TRI-Survivor/backend/internal/repository"
+	"github.com/uptrace/bun"
 )
 
+var (
+	ErrInvalidSettings             = errors.New("invalid settings")
+	ErrSettingsPreconditionFailed  = errors.New("settings were modified by another request")
+	ErrSettingsProfileNotFound     = errors.New("settings profile not found")
+	ErrSettingsProfileLimitReached = errors.New("settings profile limit reached")
+	ErrSettingsProfileConflict     = errors.New("settings profile conflicts with account settings")
+)
+
+const (
+	defaultVolume = 100
+	maxVolume     = 100
+)
+
+// maxSettingsSaveAttempts If-Matchを指定しない更新が他のリクエストと競合した場合に試行する回数
+const maxSettingsSaveAttempts = 3
+
+// maxSettingsProfiles ユーザーごとに保存できる端末ごとの設定の上限
+const maxSettingsProfiles = 10
+
+// accountScopedPreferences 端末ごとに変えられない、アカウント全体に対する設定
+// プロフィールの公開範囲は他のプレイヤーから見たときの設定のため、アカウントの設定のみを参照する
+var accountScopedPreferences = []string{"profileVisibility"}
+
 type SettingsService struct {
-	repo *repository.SettingsRepository
+	repo        *repository.SettingsRepository
+	profileRepo *repository.SettingsProfileRepository
+	userRepo    *repository.UserRepository
+	txManager   *repository.TxManager
+}
+
+func NewSettingsService(repo *repository.SettingsRepository, profileRepo *repository.SettingsProfileRepository, userRepo *repository.UserRepository, txManager *repository.TxManager) *SettingsService {
+	return &SettingsService{repo: repo, profileRepo: profileRepo, userRepo: userRepo, txManager: txManager}
 }
 
-func NewSettingsService(repo *repository.SettingsRepository) *SettingsService {
-	return &SettingsService{repo: repo}
+// EffectiveSettings 端末ごとの設定をアカウントの設定に重ねた、実際に使う設定
+type EffectiveSettings struct {
+	BGMVolume   int                     `json:"bgmVolume"`
+	SEVolume    int                     `json:"seVolume"`
+	Preferences entity.Preferences      `json:"preferences"`
+	Profile     *entity.SettingsProfile `json:"profile"` // 端末ごとの設定がない場合はnull
 }
 
 // GetSettings ユーザー設定を取得する。存在しない場合はデフォルト値を返す
@@ -24,26 +65,315 @@ func (s *SettingsService) GetSettings(ctx context.Context, userID string) (*enti
 	// 設定が未作成の場合はデフォルト値を返す (DBには保存しない)
 	if settings == nil {
 		return &entity.Settings{
-			UserID:    userID,
-			BGMVolume: 100,
-			SEVolume:  100,
+			UserID:      userID,
+			BGMVolume:   defaultVolume,
+			SEVolume:    defaultVolume,
+			Preferences: entity.DefaultPreferences(),
 		}, nil
 	}
 	return settings, nil
 }
 
 // UpdateSettings ユーザー設定を更新する
-func (s *SettingsService) UpdateSettings(ctx context.Context, userID string, bgmVolume, seVolume int) (*entity.Settings, error) {
-	settings := &entity.Settings{
-		UserID:    userID,
-		BGMVolume: bgmVolume,
-		SEVolume:  seVolume,
+// preferencesがnilの場合は現在の設定を維持する。ifMatchについてはPatchSettingsを参照
+func (s *SettingsService) UpdateSettings(ctx context.Context, userID string, bgmVolume, seVolume int, preferences *entity.Preferences, ifMatch string) (*entity.Settings, error) {
+	return s.update(ctx, userID, ifMatch, func(current *entity.Settings) (*entity.Settings, error) {
+		next := *current
+		next.BGMVolume = bgmVolume
+		next.SEVolume = seVolume
+		if preferences != nil {
+			next.Preferences = *preferences
+		}
+		return &next, nil
+	})
+}
+
+// PatchSettings ユーザー設定にJSON Merge Patch (RFC 7396) を適用する
+// 項目にnullを指定した場合はデフォルト値に戻す。ただしキー割り当てにnullを指定した場合は割り当てを解除する
+// ifMatchを指定した場合、現在の設定のETagと一致しなければErrSettingsPreconditionFailedを返す
+func (s *SettingsService) PatchSettings(ctx context.Context, userID string, patch []byte, ifMatch string) (*entity.Settings, error) {
+	var p map[string]any
+	if err := json.Unmarshal(patch, &p); err != nil || p == nil {
+		return nil, fmt.Errorf("%w: patch must be a JSON object", ErrInvalidSettings)
+	}
+	unbindNullKeyBindings(p)
+	return s.update(ctx, userID, ifMatch, func(current *entity.Settings) (*entity.Settings, error) {
+		return applySettingsPatch(current, p)
+	})
+}
+
+// update 現在の設定を元に作った設定を、読み込んだ時点から更新されていない場合のみ保存する
+func (s *SettingsService) update(ctx context.Context, userID, ifMatch string, apply func(current *entity.Settings) (*entity.Settings, error)) (*entity.Settings, error) {
+	for attempt := 1; ; attempt++ {
+		current, err := s.GetSettings(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if ifMatch != "" && !etagMatches(ifMatch, current) {
+			return nil, ErrSettingsPreconditionFailed
+		}
+
+		next, err := apply(current)
+		if err != nil {
+			return nil, err
+		}
+
+		var saved bool
+		if current.UpdatedAt.IsZero() {
+			saved, err = s.repo.CreateIfNotExists(ctx, next)
+		} else {
+			saved, err = s.repo.UpdateIfUnmodified(ctx, next, current.UpdatedAt)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if saved {
+			return next, nil
+		}
+
+		// 読み込んでから保存するまでの間に他のリクエストが更新した
+		// If-Matchを指定した場合は、クライアントが読み込んだ設定とも異なるため再試行しない
+		if ifMatch != "" || attempt >= maxSettingsSaveAttempts {
+			return nil, ErrSettingsPreconditionFailed
+		}
+	}
+}
+
+// ListProfiles ユーザーの端末ごとの設定一覧を取得する
+func (s *SettingsService) ListProfiles(ctx context.Context, userID string) ([]entity.SettingsProfile, error) {
+	return s.profileRepo.FindByUserID(ctx, userID)
+}
+
+// SaveProfile 端末ごとの設定を検証して保存する（同じ端末の設定は上書きする）
+// 上書きする項目は、現在のアカウントの設定に重ねたときに有効な設定になるものに限る
+func (s *SettingsService) SaveProfile(ctx context.Context, profile *entity.SettingsProfile) (*entity.SettingsProfile, error) {
+	if containsNull(profile.Overrides) {
+		return nil, fmt.Errorf("%w: overrides must not contain null", ErrInvalidSettings)
+	}
+	if prefs, ok := profile.Overrides["preferences"].(map[string]any); ok {
+		for _, key := range accountScopedPreferences {
+			if _, ok := prefs[key]; ok {
+				return nil, fmt.Errorf("%w: preferences.%s cannot be overridden per device", ErrInvalidSettings, key)
+			}
+		}
+	}
+	settings, err := s.GetSettings(ctx, profile.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := applySettingsPatch(settings, profile.Overrides); err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		// 別の端末の設定が同時に追加されても上限を超えないよう、ユーザー行をロックしてから数える
+		user, err := s.userRepo.WithTx(tx).FindByIDForUpdate(ctx, profile.UserID)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return ErrUserNotFound
+		}
+
+		profileRepo := s.profileRepo.WithTx(tx)
+		existing, err := profileRepo.FindByDeviceID(ctx, profile.UserID, profile.DeviceID)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			count, err := profileRepo.CountByUserID(ctx, profile.UserID)
+			if err != nil {
+				return err
+			}
+			if count >= maxSettingsProfiles {
+				return ErrSettingsProfileLimitReached
+			}
+		}
+		return profileRepo.Upsert(ctx, profile)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// DeleteProfile 端末ごとの設定を削除する
+func (s *SettingsService) DeleteProfile(ctx context.Context, userID, deviceID string) error {
+	ok, err := s.profileRepo.Delete(ctx, userID, deviceID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrSettingsProfileNotFound
+	}
+	return nil
+}
+
+// ResolveSettings 端末で実際に使う設定を返す
+// 端末ごとの設定がない場合（deviceIDが空の場合を含む）はアカウントの設定をそのまま返す
+// 保存後にアカウントの設定が変わり、重ねると無効な設定になる場合はErrSettingsProfileConflictを返す
+func (s *SettingsService) ResolveSettings(ctx context.Context, userID, deviceID string) (*EffectiveSettings, error) {
+	settings, err := s.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	effective := &EffectiveSettings{
+		BGMVolume:   settings.BGMVolume,
+		SEVolume:    settings.SEVolume,
+		Preferences: settings.Preferences,
+	}
+	if deviceID == "" {
+		return effective, nil
+	}
+
+	profile, err := s.profileRepo.FindByDeviceID(ctx, userID, deviceID)
+	if err != nil || profile == nil {
+		return effective, err
+	}
+	merged, err := applySettingsPatch(settings, profile.Overrides)
+	if err != nil {
+		if errors.Is(err, ErrInvalidSettings) || errors.Is(err, ErrInvalidPreferences) {
+			return nil, fmt.Errorf("%w: %v", ErrSettingsProfileConflict, err)
+		}
+		return nil, err
+	}
+	effective.BGMVolume = merged.BGMVolume
+	effective.SEVolume = merged.SEVolume
+	effective.Preferences = merged.Preferences
+	// 以前に保存された端末ごとの設定にアカウント全体の設定が含まれていても、アカウントの設定を使う
+	effective.Preferences.ProfileVisibility = settings.Preferences.ProfileVisibility
+	effective.Profile = profile
+	return effective, nil
+}
+
+// SettingsETag 設定の更新日時から作るETag（まだ保存されていない設定は"0"）
+func SettingsETag(settings *entity.Settings) string {
+	if settings.UpdatedAt.IsZero() {
+		return `"0"`
+	}
+	return `"` + strconv.FormatInt(settings.UpdatedAt.UnixMicro(), 36) + `"`
+}
+
+// etagMatches If-Matchヘッダーの値が設定のETagと一致するかを判定する
+// 強い比較を行うため、弱いETag (W/"...") は一致しない
+func etagMatches(ifMatch string, settings *entity.Settings) bool {
+	etag := SettingsETag(settings)
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == etag || (tag == "*" && !settings.UpdatedAt.IsZero()) {
+			return true
+		}
+	}
+	return false
+}
+
+// settingsDocument PATCHで変更できる項目
+type settingsDocument struct {
+	BGMVolume   *int            `json:"bgmVolume"`
+	SEVolume    *int            `json:"seVolume"`
+	Preferences json.RawMessage `json:"preferences"`
+}
+
+// applySettingsPatch 現在の設定にパッチを適用した設定を返す
+func applySettingsPatch(current *entity.Settings, patch map[string]any) (*entity.Settings, error) {
+	prefs, err := json.Marshal(current.Preferences)
+	if err != nil {
+		return nil, err
+	}
+	var prefsDoc any
+	if err := json.Unmarshal(prefs, &prefsDoc); err != nil {
+		return nil, err
+	}
+	doc := map[string]any{
+		"bgmVolume":   current.BGMVolume,
+		"seVolume":    current.SEVolume,
+		"preferences": prefsDoc,
+	}
+
+	merged, err := json.Marshal(mergePatch(doc, patch))
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(merged))
+	dec.DisallowUnknownFields()
+	var result settingsDocument
+	if err := dec.Decode(&result); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSettings, err)
+	}
+
+	next := &entity.Settings{
+		UserID:    current.UserID,
+		BGMVolume: defaultVolume,
+		SEVolume:  defaultVolume,
+	}
+	if result.BGMVolume != nil {
+		next.BGMVolume = *result.BGMVolume
+	}
+	if result.SEVolume != nil {
+		next.SEVolume = *result.SEVolume
 	}
-	
-	if err := s.repo.Upsert(ctx, settings); err != nil {
+	if next.BGMVolume < 0 || next.BGMVolume > maxVolume || next.SEVolume < 0 || next.SEVolume > maxVolume {
+		return nil, fmt.Errorf("%w: volume must be between 0 and %d", ErrInvalidSettings, maxVolume)
+	}
+
+	if len(result.Preferences) == 0 {
+		result.Preferences = json.RawMessage("{}")
+	}
+	preferences, err := DecodePreferences(result.Preferences)
+	if err != nil {
 		return nil, err
 	}
-	
-	// 最新の状態（CreatedAtなど）を再取得して返す
-	return s.GetSettings(ctx, userID)
+	next.Preferences = *preferences
+	return next, nil
+}
+
+// mergePatch targetにJSON Merge Patchを適用した値を返す（targetは変更されることがある）
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatch(t[k], v)
+		}
+	}
+	return t
+}
+
+// unbindNullKeyBindings パッチでnullを指定したキー割り当てを、割り当てなし（空文字）に置き換える
+// nullのままではデフォルトのキーが補われ、割り当てを解除できないため
+func unbindNullKeyBindings(patch map[string]any) {
+	prefs, ok := patch["preferences"].(map[string]any)
+	if !ok {
+		return
+	}
+	bindings, ok := prefs["keyBindings"].(map[string]any)
+	if !ok {
+		return
+	}
+	for action, code := range bindings {
+		if code == nil {
+			bindings[action] = ""
+		}
+	}
+}
+
+// containsNull JSONのオブジェクトにnullの値が含まれるかを判定する
+func containsNull(v map[string]any) bool {
+	for _, e := range v {
+		if e == nil {
+			return true
+		}
+		if m, ok := e.(map[string]any); ok && containsNull(m) {
+			return true
+		}
+	}
+	return false
 }
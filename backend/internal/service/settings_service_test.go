@@ -0,0 +1,257 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+)
+
+func TestMergePatch(t *testing.T) {
+	// RFC 7396 Appendix Aの例
+	tests := []struct {
+		target string
+		patch  string
+		want   string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		var target, patch, want any
+		mustUnmarshal(t, tt.target, &target)
+		mustUnmarshal(t, tt.patch, &patch)
+		mustUnmarshal(t, tt.want, &want)
+
+		if got := mergePatch(target, patch); !reflect.DeepEqual(got, want) {
+			t.Errorf("mergePatch(%s, %s) = %v, want %s", tt.target, tt.patch, got, tt.want)
+		}
+	}
+}
+
+func TestApplySettingsPatch(t *testing.T) {
+	// デフォルト値から変更済みの設定
+	newCurrent := func() *entity.Settings {
+		prefs := entity.DefaultPreferences()
+		prefs.InputMode = entity.InputModeKeyboard
+		prefs.KeyBindings[entity.KeyActionMoveUp] = "ArrowUp"
+		prefs.HandTracking.Sensitivity = 2
+		prefs.HandTracking.Calibration = &entity.HandCalibration{CenterX: 0.4, CenterY: 0.6, Range: 0.3}
+		prefs.Language = entity.LanguageEnglish
+		prefs.Theme = entity.ThemeDark
+		prefs.Display.ShowFPS = true
+		prefs.Display.UIScale = 1.5
+		return &entity.Settings{UserID: "user-1", BGMVolume: 30, SEVolume: 40, Preferences: prefs}
+	}
+
+	tests := []struct {
+		name    string
+		patch   string
+		wantErr error
+		check   func(t *testing.T, got *entity.Settings)
+	}{
+		{
+			name:  "empty patch",
+			patch: `{}`,
+			check: func(t *testing.T, got *entity.Settings) {
+				if want := newCurrent(); !reflect.DeepEqual(got, want) {
+					t.Errorf("got %+v, want %+v", got, want)
+				}
+			},
+		},
+		{
+			name:  "replace volume",
+			patch: `{"bgmVolume":10}`,
+			check: func(t *testing.T, got *entity.Settings) {
+				if got.BGMVolume != 10 || got.SEVolume != 40 {
+					t.Errorf("volumes = %d, %d, want 10, 40", got.BGMVolume, got.SEVolume)
+				}
+				if got.Preferences.Language != entity.LanguageEnglish {
+					t.Errorf("Language = %q, want %q", got.Preferences.Language, entity.LanguageEnglish)
+				}
+			},
+		},
+		{
+			name:  "null resets volume",
+			patch: `{"bgmVolume":null}`,
+			check: func(t *testing.T, got *entity.Settings) {
+				if got.BGMVolume != defaultVolume || got.SEVolume != 40 {
+					t.Errorf("volumes = %d, %d, want %d, 40", got.BGMVolume, got.SEVolume, defaultVolume)
+				}
+			},
+		},
+		{
+			name:  "null resets preferences",
+			patch: `{"preferences":null}`,
+			check: func(t *testing.T, got *entity.Settings) {
+				if want := entity.DefaultPreferences(); !reflect.DeepEqual(got.Preferences, want) {
+					t.Errorf("Preferences = %+v, want %+v", got.Preferences, want)
+				}
+				if got.BGMVolume != 30 {
+					t.Errorf("BGMVolume = %d, want 30", got.BGMVolume)
+				}
+			},
+		},
+		{
+			name:  "null resets a preference",
+			patch: `{"preferences":{"language":null}}`,
+			check: func(t *testing.T, got *entity.Settings) {
+				if got.Preferences.Language != entity.LanguageJapanese {
+					t.Errorf("Language = %q, want %q", got.Preferences.Language, entity.LanguageJapanese)
+				}
+				if got.Preferences.Theme != entity.ThemeDark {
+					t.Errorf("Theme = %q, want %q", got.Preferences.Theme, entity.ThemeDark)
+				}
+			},
+		},
+		{
+			name:  "nested object",
+			patch: `{"preferences":{"display":{"showFps":false}}}`,
+			check: func(t *testing.T, got *entity.Settings) {
+				d := got.Preferences.Display
+				if d.ShowFPS || d.UIScale != 1.5 || !d.ShowDamageNumbers {
+					t.Errorf("Display = %+v, want showFps=false uiScale=1.5 showDamageNumbers=true", d)
+				}
+			},
+		},
+		{
+			name:  "null removes nested object",
+			patch: `{"preferences":{"handTracking":{"calibration":null}}}`,
+			check: func(t *testing.T, got *entity.Settings) {
+				ht := got.Preferences.HandTracking
+				if ht.Calibration != nil || ht.Sensitivity != 2 {
+					t.Errorf("HandTracking = %+v, want no calibration and sensitivity 2", ht)
+				}
+			},
+		},
+		{
+			name:  "null unbinds a key",
+			patch: `{"preferences":{"keyBindings":{"pause":null}}}`,
+			check: func(t *testing.T, got *entity.Settings) {
+				kb := got.Preferences.KeyBindings
+				if kb[entity.KeyActionPause] != "" || kb[entity.KeyActionMoveUp] != "ArrowUp" {
+					t.Errorf("KeyBindings = %v, want pause unbound and moveUp ArrowUp", kb)
+				}
+			},
+		},
+		{
+			name:  "null resets key bindings",
+			patch: `{"preferences":{"keyBindings":null}}`,
+			check: func(t *testing.T, got *entity.Settings) {
+				if want := entity.DefaultPreferences().KeyBindings; !reflect.DeepEqual(got.Preferences.KeyBindings, want) {
+					t.Errorf("KeyBindings = %v, want %v", got.Preferences.KeyBindings, want)
+				}
+			},
+		},
+		{
+			name:  "rebind a key",
+			patch: `{"preferences":{"keyBindings":{"moveDown":"ArrowDown"}}}`,
+			check: func(t *testing.T, got *entity.Settings) {
+				kb := got.Preferences.KeyBindings
+				if kb[entity.KeyActionMoveDown] != "ArrowDown" || kb[entity.KeyActionMoveUp] != "ArrowUp" {
+					t.Errorf("KeyBindings = %v, want moveDown ArrowDown and moveUp ArrowUp", kb)
+				}
+			},
+		},
+		{name: "duplicate key", patch: `{"preferences":{"keyBindings":{"moveDown":"ArrowUp"}}}`, wantErr: ErrInvalidPreferences},
+		{name: "unknown preference", patch: `{"preferences":{"volume":1}}`, wantErr: ErrInvalidPreferences},
+		{name: "unknown field", patch: `{"volume":1}`, wantErr: ErrInvalidSettings},
+		{name: "volume out of range", patch: `{"seVolume":101}`, wantErr: ErrInvalidSettings},
+		{name: "volume wrong type", patch: `{"bgmVolume":"loud"}`, wantErr: ErrInvalidSettings},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch map[string]any
+			mustUnmarshal(t, tt.patch, &patch)
+			unbindNullKeyBindings(patch)
+
+			current := newCurrent()
+			got, err := applySettingsPatch(current, patch)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("applySettingsPatch(%s) error = %v, want %v", tt.patch, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applySettingsPatch(%s) error = %v", tt.patch, err)
+			}
+			if got.UserID != current.UserID {
+				t.Errorf("UserID = %q, want %q", got.UserID, current.UserID)
+			}
+			tt.check(t, got)
+
+			if !reflect.DeepEqual(current, newCurrent()) {
+				t.Errorf("current settings were modified: %+v", current)
+			}
+		})
+	}
+}
+
+func TestEtagMatches(t *testing.T) {
+	saved := &entity.Settings{UpdatedAt: time.Date(2026, 1, 2, 3, 4, 5, 6000, time.UTC)}
+	unsaved := &entity.Settings{}
+	etag := SettingsETag(saved)
+
+	tests := []struct {
+		name     string
+		ifMatch  string
+		settings *entity.Settings
+		want     bool
+	}{
+		{"same etag", etag, saved, true},
+		{"in list", `"abc", ` + etag, saved, true},
+		{"surrounding spaces", "  " + etag + " ", saved, true},
+		{"different etag", `"abc"`, saved, false},
+		{"unquoted", etag[1 : len(etag)-1], saved, false},
+		{"weak etag", "W/" + etag, saved, false},
+		{"any saved", "*", saved, true},
+		{"any unsaved", "*", unsaved, false},
+		{"unsaved etag", `"0"`, unsaved, true},
+		{"unsaved etag against saved", `"0"`, saved, false},
+		{"weak unsaved etag", `W/"0"`, unsaved, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := etagMatches(tt.ifMatch, tt.settings); got != tt.want {
+				t.Errorf("etagMatches(%q) = %v, want %v", tt.ifMatch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSettingsETag(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 6000, time.UTC)
+	if got := SettingsETag(&entity.Settings{}); got != `"0"` {
+		t.Errorf("SettingsETag(unsaved) = %s, want \"0\"", got)
+	}
+	if SettingsETag(&entity.Settings{UpdatedAt: at}) != SettingsETag(&entity.Settings{UpdatedAt: at}) {
+		t.Error("SettingsETag is not stable for the same updatedAt")
+	}
+	if SettingsETag(&entity.Settings{UpdatedAt: at}) == SettingsETag(&entity.Settings{UpdatedAt: at.Add(time.Microsecond)}) {
+		t.Error("SettingsETag does not change when updatedAt changes")
+	}
+}
+
+func mustUnmarshal(t *testing.T, s string, v any) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(s), v); err != nil {
+		t.Fatalf("invalid JSON %s: %v", s, err)
+	}
+}
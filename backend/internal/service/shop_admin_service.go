@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/repository"
+	"github.com/uptrace/bun"
+)
+
+const (
+	maxShopItemNameLength        = 100
+	maxShopItemDescriptionLength = 500
+	// shopSortOrderStep 並び替え時のsort_orderの間隔（間に差し込みやすいよう余裕を持たせる）
+	shopSortOrderStep = 10
+)
+
+var (
+	ErrInvalidShopItem = errors.New("invalid shop item")
+	ErrInvalidReorder  = errors.New("invalid reorder request")
+)
+
+// consumableEffects 消費アイテムとして設定できる効果
+var consumableEffects = []string{
+	entity.ConsumableEffectRevive,
+	entity.ConsumableEffectExpBoost,
+	entity.ConsumableEffectCoinDoubler,
+}
+
+// ShopItemInput 管理画面から登録・更新する商品の内容
+type ShopItemInput struct {
+	ItemName    string
+	Description string
+	ItemType    string
+	IconURL     string
+	Price       int
+	IsActive    bool
+	SkillType   string
+	LevelPrices []int
+	Effect      string
+}
+
+// ShopAuditLogPage 変更履歴の1ページ分
+type ShopAuditLogPage struct {
+	Logs       []entity.ShopAuditLog `json:"logs"`
+	NextCursor *string               `json:"nextCursor"`
+}
+
+// ShopAdminService 管理者によるショップ商品の管理を行う（すべての変更を履歴に残す）
+type ShopAdminService struct {
+	repo      *repository.ShopRepository
+	auditRepo *repository.ShopAuditLogRepository
+	txManager *repository.TxManager
+}
+
+func NewShopAdminService(repo *repository.ShopRepository, auditRepo *repository.ShopAuditLogRepository, txManager *repository.TxManager) *ShopAdminService {
+	return &ShopAdminService{repo: repo, auditRepo: auditRepo, txManager: txManager}
+}
+
+// ListItems 販売停止中のものも含めて全商品を表示順に取得する
+func (s *ShopAdminService) ListItems(ctx context.Context) ([]entity.Shop, error) {
+	return s.repo.FindAllIncludingInactive(ctx)
+}
+
+// CreateItem 商品を登録する（表示順は末尾）
+func (s *ShopAdminService) CreateItem(ctx context.Context, actorID string, input ShopItemInput) (*entity.Shop, error) {
+	shop := &entity.Shop{
+		ItemName:    input.ItemName,
+		Description: input.Description,
+		ItemType:    input.ItemType,
+		IconURL:     input.IconURL,
+		Price:       input.Price,
+		IsActive:    input.IsActive,
+		SkillType:   input.SkillType,
+		Effect:      input.Effect,
+	}
+	if input.SkillType != "" {
+		shop.LevelPrices = input.LevelPrices
+		shop.MaxLevel = len(input.LevelPrices)
+		if len(input.LevelPrices) > 0 {
+			shop.Price = input.LevelPrices[0]
+		}
+	}
+	if err := validateShopItem(shop); err != nil {
+		return nil, err
+	}
+
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		repo := s.repo.WithTx(tx)
+		// 並び替えと同時に行われても表示順が重複しないようにする
+		if err := repo.LockCatalog(ctx); err != nil {
+			return err
+		}
+		items, err := repo.FindAllIncludingInactive(ctx)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if shop.IsUpgrade() && item.SkillType == shop.SkillType {
+				return fmt.Errorf("%w: an upgrade for %s already exists", ErrInvalidShopItem, shop.SkillType)
+			}
+			shop.SortOrder = max(shop.SortOrder, item.SortOrder+shopSortOrderStep)
+		}
+
+		if err := repo.Create(ctx, shop); err != nil {
+			return err
+		}
+		return s.audit(ctx, tx, actorID, entity.ShopAuditActionCreate, nil, shop)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return shop, nil
+}
+
+// UpdateItem 商品の表示内容（名前・説明・種類・アイコン）を更新する
+func (s *ShopAdminService) UpdateItem(ctx context.Context, actorID string, itemID int, input ShopItemInput) (*entity.Shop, error) {
+	return s.modify(ctx, actorID, itemID, entity.ShopAuditActionUpdate, func(shop *entity.Shop) ([]string, error) {
+		shop.ItemName = input.ItemName
+		shop.Description = input.Description
+		shop.ItemType = input.ItemType
+		shop.IconURL = input.IconURL
+		return []string{"item_name", "description", "item_type", "icon_url"}, nil
+	})
+}
+
+// ChangePrice 商品の価格を変更する
+// 恒久強化の場合はレベルごとの価格表を指定し、最大レベルも価格表の長さに合わせて変更する
+// 価格の指定漏れで無料にならないよう、priceはnilを許さない
+func (s *ShopAdminService) ChangePrice(ctx context.Context, actorID string, itemID int, price *int, levelPrices []int) (*entity.Shop, error) {
+	return s.modify(ctx, actorID, itemID, entity.ShopAuditActionPriceChange, func(shop *entity.Shop) ([]string, error) {
+		if !shop.IsUpgrade() {
+			if levelPrices != nil {
+				return nil, fmt.Errorf("%w: levelPrices can only be set on upgrades", ErrInvalidShopItem)
+			}
+			if price == nil {
+				return nil, fmt.Errorf("%w: price is required", ErrInvalidShopItem)
+			}
+			shop.Price = *price
+			return []string{"price"}, nil
+		}
+
+		if price != nil {
+			return nil, fmt.Errorf("%w: price cannot be set on upgrades, use levelPrices", ErrInvalidShopItem)
+		}
+		if len(levelPrices) == 0 {
+			return nil, fmt.Errorf("%w: levelPrices is required for upgrades", ErrInvalidShopItem)
+		}
+		shop.LevelPrices = levelPrices
+		shop.MaxLevel = len(levelPrices)
+		shop.Price = levelPrices[0]
+		return []string{"price", "level_prices", "max_level"}, nil
+	})
+}
+
+// SetActive 商品の販売を再開・停止する
+func (s *ShopAdminService) SetActive(ctx context.Context, actorID string, itemID int, active bool) (*entity.Shop, error) {
+	action := entity.ShopAuditActionDeactivate
+	if active {
+		action = entity.ShopAuditActionActivate
+	}
+	return s.modify(ctx, actorID, itemID, action, func(shop *entity.Shop) ([]string, error) {
+		shop.IsActive = active
+		return []string{"is_active"}, nil
+	})
+}
+
+// Reorder 指定した順に商品の表示順を振り直す（指定されなかった商品はその後ろに現在の順で並ぶ）
+func (s *ShopAdminService) Reorder(ctx context.Context, actorID string, itemIDs []int) ([]entity.Shop, error) {
+	if len(itemIDs) == 0 {
+		return nil, fmt.Errorf("%w: itemIds is required", ErrInvalidReorder)
+	}
+	seen := make(map[int]bool, len(itemIDs))
+	for _, id := range itemIDs {
+		if seen[id] {
+			return nil, fmt.Errorf("%w: duplicate item id %d", ErrInvalidReorder, id)
+		}
+		seen[id] = true
+	}
+
+	var result []entity.Shop
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		repo := s.repo.WithTx(tx)
+		// 商品の追加・他の変更と同時に行われても、表示順の重複や古い内容の履歴が残らないようにする
+		if err := repo.LockCatalog(ctx); err != nil {
+			return err
+		}
+		items, err := repo.FindAllIncludingInactiveForUpdate(ctx)
+		if err != nil {
+			return err
+		}
+		ordered := make([]entity.Shop, 0, len(items))
+		for _, id := range itemIDs {
+			i := slices.IndexFunc(items, func(item entity.Shop) bool { return item.ItemID == id })
+			if i < 0 {
+				return fmt.Errorf("%w: unknown item id %d", ErrInvalidReorder, id)
+			}
+			ordered = append(ordered, items[i])
+		}
+		for _, item := range items {
+			if !seen[item.ItemID] {
+				ordered = append(ordered, item)
+			}
+		}
+
+		for i := range ordered {
+			sortOrder := (i + 1) * shopSortOrderStep
+			if ordered[i].SortOrder == sortOrder {
+				continue
+			}
+			before := ordered[i]
+			ordered[i].SortOrder = sortOrder
+			if err := repo.Update(ctx, &ordered[i], "sort_order"); err != nil {
+				return err
+			}
+			if err := s.audit(ctx, tx, actorID, entity.ShopAuditActionReorder, &before, &ordered[i]); err != nil {
+				return err
+			}
+		}
+		result = ordered
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetAuditLogs 商品の変更履歴を新しい順に取得する
+func (s *ShopAdminService) GetAuditLogs(ctx context.Context, itemID int, cursor string, limit int) (*ShopAuditLogPage, error) {
+	var beforeID int64
+	if cursor != "" {
+		id, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, ErrInvalidCursor
+		}
+		beforeID = id
+	}
+
+	// 次ページの有無を判定するため1件多く取得する
+	logs, err := s.auditRepo.FindByItemID(ctx, itemID, beforeID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &ShopAuditLogPage{Logs: logs}
+	if len(logs) > limit {
+		page.Logs = logs[:limit]
+		next := strconv.FormatInt(logs[limit-1].ID, 10)
+		page.NextCursor = &next
+	}
+	return page, nil
+}
+
+// modify 商品を行ロックして変更し、変更前後の内容を履歴に残す
+// applyは変更したカラム名を返す
+func (s *ShopAdminService) modify(ctx context.Context, actorID string, itemID int, action string, apply func(shop *entity.Shop) ([]string, error)) (*entity.Shop, error) {
+	var updated *entity.Shop
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		repo := s.repo.WithTx(tx)
+		shop, err := repo.FindByIDForUpdate(ctx, itemID)
+		if err != nil {
+			return err
+		}
+		if shop == nil {
+			return ErrShopItemNotFound
+		}
+
+		before := *shop
+		columns, err := apply(shop)
+		if err != nil {
+			return err
+		}
+		if err := validateShopItem(shop); err != nil {
+			return err
+		}
+		if err := repo.Update(ctx, shop, columns...); err != nil {
+			return err
+		}
+		if err := s.audit(ctx, tx, actorID, action, &before, shop); err != nil {
+			return err
+		}
+		updated = shop
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *ShopAdminService) audit(ctx context.Context, tx bun.Tx, actorID, action string, before, after *entity.Shop) error {
+	return s.auditRepo.WithTx(tx).Create(ctx, &entity.ShopAuditLog{
+		ItemID:  after.ItemID,
+		ActorID: actorID,
+		Action:  action,
+		Before:  before,
+		After:   after,
+	})
+}
+
+// validateShopItem 商品の内容がショップで扱える範囲に収まっているかを検証する
+func validateShopItem(shop *entity.Shop) error {
+	if shop.ItemName == "" || utf8.RuneCountInString(shop.ItemName) > maxShopItemNameLength {
+		return fmt.Errorf("%w: itemName must be 1-%d characters", ErrInvalidShopItem, maxShopItemNameLength)
+	}
+	if utf8.RuneCountInString(shop.Description) > maxShopItemDescriptionLength {
+		return fmt.Errorf("%w: description must be at most %d characters", ErrInvalidShopItem, maxShopItemDescriptionLength)
+	}
+	if shop.ItemType == "" {
+		return fmt.Errorf("%w: itemType is required", ErrInvalidShopItem)
+	}
+	if shop.Price < 0 {
+		return fmt.Errorf("%w: price must not be negative", ErrInvalidShopItem)
+	}
+	if shop.IsUpgrade() && shop.IsConsumable() {
+		return fmt.Errorf("%w: an item cannot be both an upgrade and a consumable", ErrInvalidShopItem)
+	}
+	if shop.IsConsumable() && !slices.Contains(consumableEffects, shop.Effect) {
+		return fmt.Errorf("%w: unknown effect %q", ErrInvalidShopItem, shop.Effect)
+	}
+	if shop.IsUpgrade() {
+		if _, ok := upgradeEffects[shop.SkillType]; !ok {
+			return fmt.Errorf("%w: unknown upgrade skill type %q", ErrInvalidShopItem, shop.SkillType)
+		}
+		if len(shop.LevelPrices) == 0 || shop.MaxLevel != len(shop.LevelPrices) {
+			return fmt.Errorf("%w: levelPrices must have one price per level", ErrInvalidShopItem)
+		}
+		for _, p := range shop.LevelPrices {
+			if p < 0 {
+				return fmt.Errorf("%w: levelPrices must not be negative", ErrInvalidShopItem)
+			}
+		}
+	}
+	return nil
+}
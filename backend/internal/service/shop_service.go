@@ -2,17 +2,47 @@ package service
 
 import (
 	"context"
+	"errors"
+	"strconv"
 
 	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
 	"github.com/RiTa-23/TRI-Survivor/backend/internal/repository"
+	"github.com/uptrace/bun"
+)
+
+var (
+	ErrShopItemNotFound   = errors.New("shop item not found")
+	ErrShopItemInactive   = errors.New("shop item is not active")
+	ErrShopItemIsUpgrade  = errors.New("shop item is an upgrade")
+	ErrShopItemNotUpgrade = errors.New("shop item is not an upgrade")
+	ErrUpgradeMaxLevel    = errors.New("upgrade already at max level")
+	ErrUpgradeConflict    = errors.New("upgrade level changed concurrently")
 )
 
 type ShopService struct {
-	repo *repository.ShopRepository
+	repo        *repository.ShopRepository
+	itemRepo    *repository.ItemRepository
+	userService *UserService
+	txManager   *repository.TxManager
 }
 
-func NewShopService(repo *repository.ShopRepository) *ShopService {
-	return &ShopService{repo: repo}
+func NewShopService(repo *repository.ShopRepository, itemRepo *repository.ItemRepository, userService *UserService, txManager *repository.TxManager) *ShopService {
+	return &ShopService{repo: repo, itemRepo: itemRepo, userService: userService, txManager: txManager}
+}
+
+// PurchaseResult 購入後のコイン残高と所持アイテム
+type PurchaseResult struct {
+	Coin int          `json:"coin"`
+	Item *entity.Item `json:"item"`
+}
+
+// UpgradeResult 強化後のコイン残高とレベル
+type UpgradeResult struct {
+	Coin      int          `json:"coin"`
+	Item      *entity.Item `json:"item"`
+	Level     int          `json:"level"`
+	MaxLevel  int          `json:"maxLevel"`
+	NextPrice *int         `json:"nextPrice"` // 最大レベルの場合はnull
 }
 
 // GetShopItems ショップの全商品を取得します
@@ -24,3 +54,123 @@ func (s *ShopService) GetShopItems(ctx context.Context) ([]entity.Shop, error) {
 func (s *ShopService) GetShopItemByID(ctx context.Context, id int) (*entity.Shop, error) {
 	return s.repo.FindByID(ctx, id)
 }
+
+// Purchase 商品を1つ購入します
+// コイン残高の確認・減算と所持数の加算を1つのトランザクションで行います
+func (s *ShopService) Purchase(ctx context.Context, userID string, itemID int) (*PurchaseResult, error) {
+	result := new(PurchaseResult)
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		shop, err := s.repo.WithTx(tx).FindByIDIncludingInactive(ctx, itemID)
+		if err != nil {
+			return err
+		}
+		if shop == nil {
+			return ErrShopItemNotFound
+		}
+		if !shop.IsActive {
+			return ErrShopItemInactive
+		}
+		if shop.IsUpgrade() {
+			return ErrShopItemIsUpgrade
+		}
+
+		txn, err := s.userService.ApplyCoinChangeTx(ctx, tx, userID, CoinChange{
+			Delta:       -shop.Price,
+			Reason:      entity.CoinReasonPurchase,
+			ReferenceID: strconv.Itoa(itemID),
+		})
+		if err != nil {
+			return err
+		}
+
+		item := &entity.Item{
+			UserID:   userID,
+			ItemID:   itemID,
+			Quantity: 1,
+		}
+		if err := s.itemRepo.WithTx(tx).AddQuantity(ctx, item); err != nil {
+			return err
+		}
+		item.Shop = shop
+
+		result.Coin = txn.BalanceAfter
+		result.Item = item
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PurchaseUpgrade 恒久強化を次のレベルへ強化します
+// 価格は次のレベルに応じてショップの価格表から決まります
+func (s *ShopService) PurchaseUpgrade(ctx context.Context, userID string, itemID int) (*UpgradeResult, error) {
+	result := new(UpgradeResult)
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		shop, err := s.repo.WithTx(tx).FindByIDIncludingInactive(ctx, itemID)
+		if err != nil {
+			return err
+		}
+		if shop == nil {
+			return ErrShopItemNotFound
+		}
+		if !shop.IsActive {
+			return ErrShopItemInactive
+		}
+		if !shop.IsUpgrade() {
+			return ErrShopItemNotUpgrade
+		}
+
+		itemRepo := s.itemRepo.WithTx(tx)
+		owned, err := itemRepo.FindByUserAndItemID(ctx, userID, itemID)
+		if err != nil {
+			return err
+		}
+		current := 0
+		if owned != nil {
+			current = owned.Quantity
+		}
+		price, ok := shop.PriceForLevel(current + 1)
+		if !ok {
+			return ErrUpgradeMaxLevel
+		}
+
+		// 同時に強化された場合に二重に課金しないよう、レベルが変わっていないときだけ更新する
+		item := &entity.Item{
+			UserID:   userID,
+			ItemID:   itemID,
+			Quantity: current + 1,
+		}
+		updated, err := itemRepo.SetLevelIfCurrent(ctx, item, current)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return ErrUpgradeConflict
+		}
+		item.Shop = shop
+
+		txn, err := s.userService.ApplyCoinChangeTx(ctx, tx, userID, CoinChange{
+			Delta:       -price,
+			Reason:      entity.CoinReasonPurchase,
+			ReferenceID: strconv.Itoa(itemID),
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Coin = txn.BalanceAfter
+		result.Item = item
+		result.Level = item.Quantity
+		result.MaxLevel = shop.MaxLevel
+		if next, ok := shop.PriceForLevel(item.Quantity + 1); ok {
+			result.NextPrice = &next
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
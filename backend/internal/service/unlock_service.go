@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
+	"github.com/RiTa-23/TRI-Survivor/backend/internal/repository"
+	"github.com/uptrace/bun"
+)
+
+var (
+	ErrUnlockableNotFound   = errors.New("unlockable not found")
+	ErrUnlockNotPurchasable = errors.New("unlockable cannot be purchased with coins")
+	ErrAlreadyUnlocked      = errors.New("already unlocked")
+)
+
+type UnlockService struct {
+	repo            *repository.UnlockableRepository
+	achievementRepo *repository.AchievementRepository
+	runRepo         *repository.RunRepository
+	userService     *UserService
+	txManager       *repository.TxManager
+}
+
+func NewUnlockService(repo *repository.UnlockableRepository, achievementRepo *repository.AchievementRepository, runRepo *repository.RunRepository, userService *UserService, txManager *repository.TxManager) *UnlockService {
+	return &UnlockService{repo: repo, achievementRepo: achievementRepo, runRepo: runRepo, userService: userService, txManager: txManager}
+}
+
+// UnlockableStatus 解放対象と、ユーザーの解放状況
+type UnlockableStatus struct {
+	entity.Unlockable
+	Unlocked   bool       `json:"unlocked"`
+	UnlockedAt *time.Time `json:"unlockedAt"`
+}
+
+// PickableSkills ラン開始時に選択できる武器・必殺技
+type PickableSkills struct {
+	Weapons  []string `json:"weapons"`
+	Specials []string `json:"specials"`
+}
+
+// UnlockPurchaseResult コインでの解放後の残高と解放対象
+type UnlockPurchaseResult struct {
+	Coin       int                `json:"coin"`
+	Unlockable *entity.Unlockable `json:"unlockable"`
+}
+
+// GetUnlockables 解放対象の一覧と、ユーザーの解放状況を取得する
+// 実績・レベル条件による解放はラン終了時にEvaluateUnlocksTxで行うため、ここでは読み込みのみ行う
+func (s *UnlockService) GetUnlockables(ctx context.Context, userID string) ([]UnlockableStatus, error) {
+	unlockables, err := s.repo.FindActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.FindUnlockedByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	unlocked := make(map[int]entity.UserUnlock, len(rows))
+	for _, uu := range rows {
+		unlocked[uu.UnlockableID] = uu
+	}
+
+	result := make([]UnlockableStatus, 0, len(unlockables))
+	for _, u := range unlockables {
+		status := UnlockableStatus{Unlockable: u}
+		if u.ConditionType == entity.UnlockConditionDefault {
+			status.Unlocked = true
+		} else if uu, ok := unlocked[u.ID]; ok {
+			status.Unlocked = true
+			status.UnlockedAt = &uu.UnlockedAt
+		}
+		result = append(result, status)
+	}
+	return result, nil
+}
+
+// GetPickableSkills ユーザーがラン開始時に選択できる武器・必殺技のコードを取得する
+func (s *UnlockService) GetPickableSkills(ctx context.Context, userID string) (*PickableSkills, error) {
+	statuses, err := s.GetUnlockables(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	pickable := &PickableSkills{Weapons: []string{}, Specials: []string{}}
+	for _, st := range statuses {
+		if !st.Unlocked {
+			continue
+		}
+		switch st.Kind {
+		case entity.UnlockableKindWeapon:
+			pickable.Weapons = append(pickable.Weapons, st.Code)
+		case entity.UnlockableKindSpecial:
+			pickable.Specials = append(pickable.Specials, st.Code)
+		}
+	}
+	return pickable, nil
+}
+
+// PurchaseUnlock コインで武器・必殺技を解放する
+func (s *UnlockService) PurchaseUnlock(ctx context.Context, userID, code string) (*UnlockPurchaseResult, error) {
+	result := new(UnlockPurchaseResult)
+	err := s.txManager.RunInTx(ctx, func(ctx context.Context, tx bun.Tx) error {
+		repo := s.repo.WithTx(tx)
+		unlockable, err := repo.FindByCode(ctx, code)
+		if err != nil {
+			return err
+		}
+		if unlockable == nil {
+			return ErrUnlockableNotFound
+		}
+		if unlockable.ConditionType == entity.UnlockConditionDefault {
+			return ErrAlreadyUnlocked
+		}
+		if unlockable.ConditionType != entity.UnlockConditionCoin {
+			return ErrUnlockNotPurchasable
+		}
+
+		ok, err := repo.Unlock(ctx, &entity.UserUnlock{
+			UserID:       userID,
+			UnlockableID: unlockable.ID,
+			Source:       entity.UnlockSourceCoin,
+		})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrAlreadyUnlocked
+		}
+
+		txn, err := s.userService.ApplyCoinChangeTx(ctx, tx, userID, CoinChange{
+			Delta:       -unlockable.Price,
+			Reason:      entity.CoinReasonUnlockPurchase,
+			ReferenceID: unlockable.Code,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Coin = txn.BalanceAfter
+		result.Unlockable = unlockable
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// EvaluateUnlocksTx トランザクション内で実績・レベル条件を満たしている未解放のものを解放し、新たに解放したものを返す
+// ランの結果と実績を保存した後に呼び出すこと
+func (s *UnlockService) EvaluateUnlocksTx(ctx context.Context, tx bun.Tx, userID string) ([]entity.Unlockable, error) {
+	repo := s.repo.WithTx(tx)
+	unlockables, err := repo.FindActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := repo.FindUnlockedByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	unlocked := make(map[int]bool, len(rows))
+	for _, uu := range rows {
+		unlocked[uu.UnlockableID] = true
+	}
+
+	// 条件の判定に必要なデータは、判定が必要になったときに一度だけ取得する
+	var achievementCodes []string
+	var careerStats *entity.CareerStats
+	newlyUnlocked := []entity.Unlockable{}
+	for _, u := range unlockables {
+		if unlocked[u.ID] {
+			continue
+		}
+
+		var met bool
+		var source string
+		switch u.ConditionType {
+		case entity.UnlockConditionAchievement:
+			if achievementCodes == nil {
+				achievementCodes, err = s.achievementRepo.WithTx(tx).FindUnlockedCodes(ctx, userID)
+				if err != nil {
+					return nil, err
+				}
+			}
+			met = slices.Contains(achievementCodes, u.Params.Achievement)
+			source = entity.UnlockSourceAchievement
+		case entity.UnlockConditionLevel:
+			if careerStats == nil {
+				careerStats, err = s.runRepo.WithTx(tx).AggregateStats(ctx, userID)
+				if err != nil {
+					return nil, err
+				}
+			}
+			met = careerStats.BestLevel >= u.Params.Level
+			source = entity.UnlockSourceLevel
+		}
+		if !met {
+			continue
+		}
+
+		ok, err := repo.Unlock(ctx, &entity.UserUnlock{
+			UserID:       userID,
+			UnlockableID: u.ID,
+			Source:       source,
+			UnlockedAt:   time.Now(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			newlyUnlocked = append(newlyUnlocked, u)
+		}
+	}
+	return newlyUnlocked, nil
+}
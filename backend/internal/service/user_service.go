@@ -2,17 +2,41 @@ package service
 
 import (
 	"context"
+	"errors"
+	"strconv"
 
 	"github.com/RiTa-23/TRI-Survivor/backend/internal/entity"
 	"github.com/RiTa-23/TRI-Survivor/backend/internal/repository"
+	"github.com/uptrace/bun"
+)
+
+var (
+	ErrUserNotFound      = errors.New("user not found")
+	ErrInsufficientCoins = errors.New("insufficient coins")
+	ErrInvalidCursor     = errors.New("invalid cursor")
 )
 
 type UserService struct {
-	repo *repository.UserRepository
+	repo       *repository.UserRepository
+	coinTxRepo *repository.CoinTransactionRepository
+	txManager  *repository.TxManager
+}
+
+func NewUserService(repo *repository.UserRepository, coinTxRepo *repository.CoinTransactionRepository, txManager *repository.TxManager) *UserService {
+	return &UserService{repo: repo, coinTxRepo: coinTxRepo, txManager: txManager}
 }
 
-func NewUserService(repo *repository.UserRepository) *UserService {
-	return &UserService{repo: repo}
+// CoinChange コイン残高の変更内容
+type CoinChange struct {
+	Delta       int
+	Reason      string
+	ReferenceID string
+}
+
+// CoinHistory コイン履歴の1ページ分
+type CoinHistory struct {
+	Transactions []entity.CoinTransaction `json:"transactions"`
+	NextCursor   *string                  `json:"nextCursor"`
 }
 
 // SyncUser ユーザー情報を同期する（存在しなければ作成、あれば更新）
@@ -38,10 +62,68 @@ func (s *UserService) GetUser(ctx context.Context, id string) (*entity.User, err
 	return s.repo.FindByID(ctx, id)
 }
 
-// AddCoin ユーザーにコインを追加する
-func (s *UserService) AddCoin(ctx context.Context, id string, amount int) (*entity.User, error) {
-	if err := s.repo.UpdateCoin(ctx, id, amount); err != nil {
+// ApplyCoinChangeTx トランザクション内でコイン残高を変更し、同じトランザクションで台帳に記録する
+// 残高の変更は必ずこのメソッドを経由させること
+func (s *UserService) ApplyCoinChangeTx(ctx context.Context, tx bun.Tx, userID string, change CoinChange) (*entity.CoinTransaction, error) {
+	userRepo := s.repo.WithTx(tx)
+
+	// 同時更新による残高の不整合を防ぐため、ユーザー行をロックしてから残高を確認する
+	user, err := userRepo.FindByIDForUpdate(ctx, userID)
+	if err != nil {
 		return nil, err
 	}
-	return s.GetUser(ctx, id)
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if user.Coin+change.Delta < 0 {
+		return nil, ErrInsufficientCoins
+	}
+	// 増減がない場合（無料アイテムなど）は台帳に記録しない
+	if change.Delta == 0 {
+		return &entity.CoinTransaction{UserID: userID, Reason: change.Reason, ReferenceID: change.ReferenceID, BalanceAfter: user.Coin}, nil
+	}
+
+	balance, err := userRepo.UpdateCoin(ctx, userID, change.Delta)
+	if err != nil {
+		return nil, err
+	}
+
+	txn := &entity.CoinTransaction{
+		UserID:       userID,
+		Delta:        change.Delta,
+		Reason:       change.Reason,
+		ReferenceID:  change.ReferenceID,
+		BalanceAfter: balance,
+	}
+	if err := s.coinTxRepo.WithTx(tx).Create(ctx, txn); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+// GetCoinHistory コイン履歴を新しい順に取得する
+// cursorには前ページのNextCursorを指定する（空文字の場合は先頭から）
+func (s *UserService) GetCoinHistory(ctx context.Context, userID string, cursor string, limit int) (*CoinHistory, error) {
+	var beforeID int64
+	if cursor != "" {
+		id, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, ErrInvalidCursor
+		}
+		beforeID = id
+	}
+
+	// 次ページの有無を判定するため1件多く取得する
+	txns, err := s.coinTxRepo.FindByUserID(ctx, userID, beforeID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &CoinHistory{Transactions: txns}
+	if len(txns) > limit {
+		history.Transactions = txns[:limit]
+		next := strconv.FormatInt(txns[limit-1].ID, 10)
+		history.NextCursor = &next
+	}
+	return history, nil
 }
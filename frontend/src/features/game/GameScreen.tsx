@@ -5,6 +5,7 @@ import { type PlayerStats, SkillType, type SkillOption, SKILL_DEFINITIONS } from
 import { Button } from "@/components/ui/button";
 import { ArrowUp, Coins, Heart, Zap, Clock, Skull } from "lucide-react";
 import { SkillSelectionModal } from "./SkillSelectionModal";
+import { api } from "@/lib/api";
 
 import { useGameStore } from "@/store/gameStore";
 
@@ -77,6 +78,9 @@ export default function GameScreen() {
     // Debounce/Throttle confirm action to prevent double firing
     const lastConfirmTimeRef = useRef<number>(0);
 
+    // Run issued by the backend; its id and nonce are required to submit the result
+    const runRef = useRef<Promise<{ id: string; nonce: string } | null> | null>(null);
+
     useEffect(() => {
         if (!containerRef.current || !videoRef.current || !canvasRef.current) return;
 
@@ -87,6 +91,16 @@ export default function GameScreen() {
             setSelectedIndex(null);
             setIsTutorialVisible(true);
 
+            // Start the run on the backend so the result can be submitted for rewards
+            runRef.current = api.post('/runs', {}, {
+                headers: { 'Idempotency-Key': crypto.randomUUID() },
+            })
+                .then((response) => ({ id: response.data.id as string, nonce: response.data.nonce as string }))
+                .catch((error) => {
+                    console.error("Failed to start run:", error);
+                    return null;
+                });
+
             // Destroy previous instance if it exists
             if (gameAppRef.current) {
                 gameAppRef.current.destroy();
@@ -200,8 +214,9 @@ export default function GameScreen() {
                     setSelectedIndex(initialIndex);
                 },
                 // onGameEnd Callback
-                (finalStats: PlayerStats, isClear: boolean) => {
-                    navigate("/result", { state: { stats: finalStats, isClear } });
+                async (finalStats: PlayerStats, isClear: boolean) => {
+                    const run = await runRef.current;
+                    navigate("/result", { state: { stats: finalStats, isClear, run } });
                 },
                 selectedWeapon,
                 selectedSpecialMove
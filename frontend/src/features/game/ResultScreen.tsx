@@ -11,31 +11,45 @@ export default function ResultScreen() {
     const location = useLocation();
     const stats = location.state?.stats as PlayerStats | undefined;
     const isClear = location.state?.isClear as boolean | undefined;
+    const run = location.state?.run as { id: string; nonce: string } | null | undefined;
 
     // API Call State
     const hasSavedRef = useRef(false);
+    // Same key for retries of this result so the backend credits the reward only once
+    const idempotencyKeyRef = useRef(crypto.randomUUID());
 
     useEffect(() => {
-        if (!stats || hasSavedRef.current) return;
+        if (!stats || !run || hasSavedRef.current) return;
 
-        const saveCoins = async () => {
+        const finishRun = async () => {
             hasSavedRef.current = true; // Prevent concurrent calls immediately
             try {
-                if (stats.coins > 0) {
-                    const response = await api.post('/users/me/coins', { amount: stats.coins });
-                    // Update store with latest coin amount from backend
-                    if (response.data && typeof response.data.coin === 'number') {
-                        useGameStore.getState().setCoins(response.data.coin);
-                    }
+                // The backend validates the result and grants the coin reward
+                await api.post(`/runs/${run.id}/finish`, {
+                    nonce: run.nonce,
+                    time: stats.time,
+                    killCount: stats.killCount,
+                    level: stats.level,
+                    coins: stats.coins,
+                    weapons: stats.weapons,
+                    passives: stats.passives,
+                    activeSpecialType: stats.activeSpecialType,
+                }, {
+                    headers: { 'Idempotency-Key': idempotencyKeyRef.current },
+                });
+                // Update store with latest coin amount from backend
+                const response = await api.get('/users/me');
+                if (response.data && typeof response.data.coin === 'number') {
+                    useGameStore.getState().setCoins(response.data.coin);
                 }
             } catch (error) {
-                console.error("Failed to save coins:", error);
+                console.error("Failed to finish run:", error);
                 hasSavedRef.current = false; // Allow retry on failure
             }
         };
 
-        saveCoins();
-    }, [stats]);
+        finishRun();
+    }, [stats, run]);
 
     if (!stats) {
         return (